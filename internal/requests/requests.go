@@ -9,6 +9,43 @@ import (
 	"github.com/cello-proj/cello/internal/validations"
 )
 
+// ArchiveStaleTargets request.
+type ArchiveStaleTargets struct {
+	Days    int             `json:"days"`
+	Targets []ArchiveTarget `json:"targets"`
+}
+
+// ArchiveTarget identifies a target to archive.
+type ArchiveTarget struct {
+	ProjectName string `json:"project_name" valid:"required~project_name is required"`
+	TargetName  string `json:"target_name" valid:"required~target_name is required"`
+}
+
+// Validate validates ArchiveStaleTargets.
+func (req ArchiveStaleTargets) Validate() error {
+	v := []func() error{
+		func() error {
+			if req.Days < 1 {
+				return errors.New("days must be a positive integer")
+			}
+			return nil
+		},
+		func() error {
+			if len(req.Targets) == 0 {
+				return errors.New("targets is required")
+			}
+			return nil
+		},
+	}
+
+	for _, t := range req.Targets {
+		t := t
+		v = append(v, func() error { return validations.ValidateStruct(t) })
+	}
+
+	return validations.Validate(v...)
+}
+
 // CreateWorkflow request.
 // TODO: diff and sync should have separate validations/structs for validations
 type CreateWorkflow struct {
@@ -133,6 +170,19 @@ func (req CreateProject) Validate() error {
 	return validations.Validate(v...)
 }
 
+// SetReadOnly request.
+type SetReadOnly struct {
+	ReadOnly *bool `json:"read_only"`
+}
+
+// Validate validates SetReadOnly.
+func (req SetReadOnly) Validate() error {
+	if req.ReadOnly == nil {
+		return errors.New("read_only is required")
+	}
+	return nil
+}
+
 // TargetOperation represents a target operation request.
 // TODO evaluate this vs. CreateGitWorkflow.
 type TargetOperation struct {
@@ -589,3 +589,83 @@ func TestCreateProjectValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestSetReadOnlyValidate(t *testing.T) {
+	enabled := true
+
+	tests := []struct {
+		name    string
+		req     SetReadOnly
+		wantErr error
+	}{
+		{
+			name: "valid",
+			req: SetReadOnly{
+				ReadOnly: &enabled,
+			},
+		},
+		{
+			name:    "missing read_only",
+			req:     SetReadOnly{},
+			wantErr: errors.New("read_only is required"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr != nil {
+				assert.EqualError(t, tt.req.Validate(), tt.wantErr.Error())
+			} else {
+				assert.Equal(t, tt.wantErr, tt.req.Validate())
+			}
+		})
+	}
+}
+
+func TestArchiveStaleTargetsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ArchiveStaleTargets
+		wantErr error
+	}{
+		{
+			name: "valid",
+			req: ArchiveStaleTargets{
+				Days:    90,
+				Targets: []ArchiveTarget{{ProjectName: "project1", TargetName: "target1"}},
+			},
+		},
+		{
+			name: "days must be positive",
+			req: ArchiveStaleTargets{
+				Targets: []ArchiveTarget{{ProjectName: "project1", TargetName: "target1"}},
+			},
+			wantErr: errors.New("days must be a positive integer"),
+		},
+		{
+			name: "missing targets",
+			req: ArchiveStaleTargets{
+				Days: 90,
+			},
+			wantErr: errors.New("targets is required"),
+		},
+		{
+			name: "missing target name",
+			req: ArchiveStaleTargets{
+				Days:    90,
+				Targets: []ArchiveTarget{{ProjectName: "project1"}},
+			},
+			wantErr: errors.New("target_name is required"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr != nil {
+				assert.EqualError(t, tt.req.Validate(), tt.wantErr.Error())
+			} else {
+				assert.Equal(t, tt.wantErr, tt.req.Validate())
+			}
+		})
+	}
+}
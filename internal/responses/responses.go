@@ -1,5 +1,12 @@
 package responses
 
+// ArchiveStaleTargets represents the responses for ArchiveStaleTargets.
+// Requested targets which are no longer stale are skipped.
+type ArchiveStaleTargets struct {
+	Archived []StaleTarget   `json:"archived"`
+	Skipped  []SkippedTarget `json:"skipped"`
+}
+
 // Diff represents the responses for Diff.
 type Diff TargetOperation
 
@@ -32,6 +39,34 @@ type GetWorkflowStatus struct {
 	Finished string `json:"finished"`
 }
 
+// ReadOnly represents the responses for getting or setting read-only mode.
+type ReadOnly struct {
+	ReadOnly bool `json:"read_only"`
+}
+
+// SkippedTarget represents a target left out of a stale targets operation.
+type SkippedTarget struct {
+	ProjectName string `json:"project_name"`
+	TargetName  string `json:"target_name"`
+	Reason      string `json:"reason"`
+}
+
+// StaleTarget represents a target which is a cleanup candidate.
+// LastActivity is empty when the target has no workflows.
+type StaleTarget struct {
+	ProjectName  string `json:"project_name"`
+	TargetName   string `json:"target_name"`
+	RoleArn      string `json:"role_arn"`
+	LastActivity string `json:"last_activity"`
+}
+
+// StaleTargets represents the responses for the stale targets report.
+// Targets whose role could not be checked are skipped.
+type StaleTargets struct {
+	Candidates []StaleTarget   `json:"candidates"`
+	Skipped    []SkippedTarget `json:"skipped"`
+}
+
 // Sync represents the responses for Sync.
 type Sync TargetOperation
 
@@ -2,10 +2,25 @@ package types
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/cello-proj/cello/internal/validations"
 )
 
+// CredentialTypes are the supported target credential types.
+var CredentialTypes = []string{"assumed_role", "federation_token", "iam_user", "session_token"}
+
+// IsValidCredentialType returns true if the credential type is supported.
+func IsValidCredentialType(credentialType string) bool {
+	for _, t := range CredentialTypes {
+		if t == credentialType {
+			return true
+		}
+	}
+	return false
+}
+
 type Target struct {
 	Name       string           `json:"name" valid:"required~name is required,alphanumunderscore~name must be alphanumeric underscore,stringlength(4|32)~name must be between 4 and 32 characters"`
 	Properties TargetProperties `json:"properties"`
@@ -17,7 +32,7 @@ type TargetProperties struct {
 	CredentialType string   `json:"credential_type" valid:"required~credential_type is required"`
 	PolicyArns     []string `json:"policy_arns"`
 	PolicyDocument string   `json:"policy_document"`
-	RoleArn        string   `json:"role_arn" valid:"required~role_arn is required"`
+	RoleArn        string   `json:"role_arn"`
 }
 
 // Validate validates Target.
@@ -41,11 +56,21 @@ func (properties TargetProperties) Validate() error {
 	v := []func() error{
 		func() error { return validations.ValidateStruct(properties) },
 		func() error {
-			if properties.CredentialType != "assumed_role" {
-				return errors.New("credential_type must be one of 'assumed_role'")
+			if !IsValidCredentialType(properties.CredentialType) {
+				return fmt.Errorf("credential_type must be one of '%s'", strings.Join(CredentialTypes, " "))
+			}
+
+			// Only assumed roles use a role, the other credential types are
+			// issued for the IAM user configured in vault.
+			if properties.CredentialType == "assumed_role" && properties.RoleArn == "" {
+				return errors.New("role_arn is required")
+			}
+
+			if properties.CredentialType != "assumed_role" && properties.RoleArn != "" {
+				return errors.New("role_arn is only allowed for credential_type 'assumed_role'")
 			}
 
-			if !validations.IsValidARN(properties.RoleArn) {
+			if properties.RoleArn != "" && !validations.IsValidARN(properties.RoleArn) {
 				return errors.New("role_arn must be a valid arn")
 			}
 
@@ -36,6 +36,30 @@ func TestTargetPropertiesValidate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid without role_arn",
+			properties: TargetProperties{
+				CredentialType: "iam_user",
+				PolicyArns: []string{
+					"arn:aws:iam::012345678901:policy/test-policy-1",
+				},
+			},
+		},
+		{
+			name: "role_arn is not allowed for other credential types",
+			properties: TargetProperties{
+				CredentialType: "session_token",
+				RoleArn:        "arn:aws:iam::012345678901:role/test-role",
+			},
+			wantErr: errors.New("role_arn is only allowed for credential_type 'assumed_role'"),
+		},
+		{
+			name: "role_arn is required for assumed_role",
+			properties: TargetProperties{
+				CredentialType: "assumed_role",
+			},
+			wantErr: errors.New("role_arn is required"),
+		},
 		{
 			name: "role_arn must be an arn",
 			properties: TargetProperties{
@@ -213,7 +237,7 @@ func TestCTargetValidate(t *testing.T) {
 				},
 				Type: "aws_account",
 			},
-			wantErr: errors.New("credential_type must be one of 'assumed_role'"),
+			wantErr: errors.New("credential_type must be one of 'assumed_role federation_token iam_user session_token'"),
 		},
 		{
 			name: "missing role_arn",
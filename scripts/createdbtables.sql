@@ -6,3 +6,25 @@ CREATE TABLE IF NOT EXISTS projects
     CONSTRAINT projects_pkey PRIMARY KEY (project)
 );
 GRANT ALL PRIVILEGES ON projects TO cello;
+CREATE TABLE IF NOT EXISTS archived_targets
+(
+    project character varying(80) NOT NULL,
+    target character varying(80) NOT NULL,
+    properties text,
+    archived_at timestamp NOT NULL
+);
+GRANT ALL PRIVILEGES ON archived_targets TO cello;
+CREATE TABLE IF NOT EXISTS read_only
+(
+    read_only boolean NOT NULL,
+    updated_at timestamp NOT NULL
+);
+GRANT ALL PRIVILEGES ON read_only TO cello;
+CREATE TABLE IF NOT EXISTS target_activity
+(
+    project character varying(80) NOT NULL,
+    target character varying(80) NOT NULL,
+    last_submitted_at timestamp NOT NULL,
+    CONSTRAINT target_activity_pkey PRIMARY KEY (project, target)
+);
+GRANT ALL PRIVILEGES ON target_activity TO cello;
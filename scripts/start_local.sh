@@ -118,6 +118,11 @@ path "aws/roles/argo-cloudops-projects-*" {
 path "aws/roles/*" {
   capabilities = [ "read", "list" ]
 }
+
+# Revoke tokens of timed out workflows
+path "auth/token/revoke-accessor" {
+  capabilities = [ "update" ]
+}
 EOF
 
 vault policy write argo-cloudops-service /tmp/argo-cloudops-policy.hcl
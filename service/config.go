@@ -7,6 +7,9 @@ import (
 	"sort"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/cello-proj/cello/internal/types"
 
 	"gopkg.in/yaml.v2"
 )
@@ -20,8 +23,38 @@ type CommandVariables struct {
 
 // Config represents the configuration.
 type Config struct {
-	Version  string
-	Commands map[string]map[string]string `yaml:"commands"`
+	Version         string
+	Commands        map[string]map[string]string `yaml:"commands"`
+	Timeouts        Timeouts                     `yaml:"timeouts"`
+	SecretScanning  SecretScanning               `yaml:"secret_scanning"`
+	CredentialTypes CredentialTypes              `yaml:"credential_types"`
+}
+
+// CredentialTypes represents the allowed target credential type config items.
+// Projects override the global allowed list, only 'assumed_role' is allowed
+// when omitted.
+type CredentialTypes struct {
+	Allowed  []string            `yaml:"allowed"`
+	Projects map[string][]string `yaml:"projects"`
+}
+
+const (
+	secretScanningModeWarn  = "warn"
+	secretScanningModeBlock = "block"
+)
+
+// SecretScanning represents the manifest secret scanning config items. Mode
+// must be one of 'warn' or 'block', manifests are not scanned when omitted.
+type SecretScanning struct {
+	Mode string `yaml:"mode"`
+}
+
+// Timeouts represents the operation timeout config items. A zero timeout
+// means operations are never timed out.
+type Timeouts struct {
+	Default    time.Duration                       `yaml:"default"`
+	Frameworks map[string]time.Duration            `yaml:"frameworks"`
+	Targets    map[string]map[string]time.Duration `yaml:"targets"`
 }
 
 func loadConfig(configFilePath string) (*Config, error) {
@@ -36,6 +69,24 @@ func loadConfig(configFilePath string) (*Config, error) {
 		return nil, err
 	}
 
+	switch config.SecretScanning.Mode {
+	case "", secretScanningModeWarn, secretScanningModeBlock:
+	default:
+		return nil, fmt.Errorf("unknown secret scanning mode '%s'", config.SecretScanning.Mode)
+	}
+
+	allowed := [][]string{config.CredentialTypes.Allowed}
+	for _, projectAllowed := range config.CredentialTypes.Projects {
+		allowed = append(allowed, projectAllowed)
+	}
+	for _, credentialTypes := range allowed {
+		for _, credentialType := range credentialTypes {
+			if !types.IsValidCredentialType(credentialType) {
+				return nil, fmt.Errorf("unknown credential type '%s'", credentialType)
+			}
+		}
+	}
+
 	return &config, nil
 }
 
@@ -75,6 +126,66 @@ func (c Config) listTypes(framework string) ([]string, error) {
 	return keys, nil
 }
 
+// Returns the operation timeout for the target, falling back to the
+// framework and then the default timeout.
+func (c Config) getTimeout(framework, projectName, targetName string) time.Duration {
+	if timeout, ok := c.Timeouts.Targets[projectName][targetName]; ok {
+		return timeout
+	}
+
+	if timeout, ok := c.Timeouts.Frameworks[framework]; ok {
+		return timeout
+	}
+
+	return c.Timeouts.Default
+}
+
+// Returns the credential types allowed for the project, falling back to the
+// global allowed credential types.
+func (c Config) allowedCredentialTypes(projectName string) []string {
+	if allowed, ok := c.CredentialTypes.Projects[projectName]; ok {
+		return allowed
+	}
+
+	if len(c.CredentialTypes.Allowed) > 0 {
+		return c.CredentialTypes.Allowed
+	}
+
+	return []string{"assumed_role"}
+}
+
+func (c Config) isCredentialTypeAllowed(projectName, credentialType string) bool {
+	for _, allowed := range c.allowedCredentialTypes(projectName) {
+		if allowed == credentialType {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if any operation timeout is configured.
+func (t Timeouts) enabled() bool {
+	if t.Default > 0 {
+		return true
+	}
+
+	for _, timeout := range t.Frameworks {
+		if timeout > 0 {
+			return true
+		}
+	}
+
+	for _, targets := range t.Targets {
+		for _, timeout := range targets {
+			if timeout > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func generateExecuteCommand(commandDefinition, environmentVariablesString string, arguments map[string][]string) (string, error) {
 	initArguments := ""
 	if _, ok := arguments["init"]; ok {
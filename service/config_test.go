@@ -2,6 +2,7 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -70,6 +71,11 @@ func TestGetCommandDefinition(t *testing.T) {
 	}
 }
 
+func TestLoadConfigSecretScanningMode(t *testing.T) {
+	_, err := loadConfig("../service/testdata/bad_secret_scanning_mode.yaml")
+	assert.EqualError(t, err, "unknown secret scanning mode 'ignore'")
+}
+
 func TestListFrameworks(t *testing.T) {
 	config, err := loadConfig(testConfigPath)
 	if err != nil {
@@ -78,3 +84,147 @@ func TestListFrameworks(t *testing.T) {
 
 	assert.Equal(t, []string{"cdk", "cool-new-framework", "terraform"}, config.listFrameworks())
 }
+
+func TestGetTimeout(t *testing.T) {
+	config, err := loadConfig(testConfigPath)
+	if err != nil {
+		t.Errorf("Unable to load config %s", err)
+	}
+
+	tests := []struct {
+		name      string
+		framework string
+		project   string
+		target    string
+		want      time.Duration
+	}{
+		{
+			name:      "target timeout",
+			framework: "cool-new-framework",
+			project:   "project1",
+			target:    "target1",
+			want:      10 * time.Minute,
+		},
+		{
+			name:      "framework timeout",
+			framework: "cool-new-framework",
+			project:   "project1",
+			target:    "target2",
+			want:      30 * time.Minute,
+		},
+		{
+			name:      "default timeout",
+			framework: "cdk",
+			project:   "project2",
+			target:    "target1",
+			want:      2 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, config.getTimeout(tt.framework, tt.project, tt.target))
+		})
+	}
+}
+
+func TestLoadConfigCredentialTypes(t *testing.T) {
+	_, err := loadConfig("../service/testdata/bad_credential_type.yaml")
+	assert.EqualError(t, err, "unknown credential type 'access_key'")
+}
+
+func TestIsCredentialTypeAllowed(t *testing.T) {
+	config, err := loadConfig(testConfigPath)
+	if err != nil {
+		t.Errorf("Unable to load config %s", err)
+	}
+
+	tests := []struct {
+		name           string
+		config         Config
+		project        string
+		credentialType string
+		want           bool
+	}{
+		{
+			name:           "globally allowed",
+			config:         *config,
+			project:        "project1",
+			credentialType: "session_token",
+			want:           true,
+		},
+		{
+			name:           "not globally allowed",
+			config:         *config,
+			project:        "project1",
+			credentialType: "iam_user",
+		},
+		{
+			name:           "allowed for project",
+			config:         *config,
+			project:        "grandfathered",
+			credentialType: "iam_user",
+			want:           true,
+		},
+		{
+			name:           "project overrides global",
+			config:         *config,
+			project:        "grandfathered",
+			credentialType: "session_token",
+		},
+		{
+			name:           "defaults to assumed role",
+			project:        "project1",
+			credentialType: "assumed_role",
+			want:           true,
+		},
+		{
+			name:           "defaults to only assumed role",
+			project:        "project1",
+			credentialType: "federation_token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.isCredentialTypeAllowed(tt.project, tt.credentialType))
+		})
+	}
+}
+
+func TestTimeoutsEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts Timeouts
+		want     bool
+	}{
+		{
+			name: "no timeouts",
+		},
+		{
+			name:     "zero timeouts",
+			timeouts: Timeouts{Frameworks: map[string]time.Duration{"cdk": 0}},
+		},
+		{
+			name:     "default timeout",
+			timeouts: Timeouts{Default: time.Hour},
+			want:     true,
+		},
+		{
+			name:     "framework timeout",
+			timeouts: Timeouts{Frameworks: map[string]time.Duration{"cdk": time.Hour}},
+			want:     true,
+		},
+		{
+			name:     "target timeout",
+			timeouts: Timeouts{Targets: map[string]map[string]time.Duration{"project1": {"target1": time.Hour}}},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.timeouts.enabled())
+		})
+	}
+}
@@ -1,19 +1,27 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cello-proj/cello/internal/requests"
+	"github.com/cello-proj/cello/internal/responses"
 	"github.com/cello-proj/cello/internal/types"
 	"github.com/cello-proj/cello/service/internal/credentials"
 	"github.com/cello-proj/cello/service/internal/db"
 	"github.com/cello-proj/cello/service/internal/env"
 	"github.com/cello-proj/cello/service/internal/git"
+	"github.com/cello-proj/cello/service/internal/iam"
+	"github.com/cello-proj/cello/service/internal/scanner"
 	"github.com/cello-proj/cello/service/internal/workflow"
 
 	"github.com/go-kit/log"
@@ -52,6 +60,92 @@ type handler struct {
 	gitClient              git.Client
 	env                    env.Vars
 	dbClient               db.Client
+	readOnly               *readOnlyMode
+	secretScanner          scanner.Scanner
+	roleChecker            iam.RoleChecker
+}
+
+// How long the shared read-only mode is cached before being read again.
+const (
+	readOnlyCacheTTL    = 5 * time.Second
+	readOnlyReadTimeout = 2 * time.Second
+)
+
+// Tracks whether the service is in read-only mode. The mode is shared by all
+// service instances through the db and cached briefly, CELLO_READ_ONLY keeps
+// an instance in read-only mode regardless. Safe for concurrent use.
+type readOnlyMode struct {
+	forced   bool
+	dbClient db.Client
+	logger   log.Logger
+
+	mu         sync.Mutex
+	enabled    bool
+	expires    time.Time
+	refreshing bool
+	// Incremented by set so an in flight refresh doesn't overwrite it.
+	version uint64
+}
+
+func newReadOnlyMode(forced bool, dbClient db.Client, logger log.Logger) *readOnlyMode {
+	return &readOnlyMode{
+		forced:   forced,
+		dbClient: dbClient,
+		logger:   logger,
+	}
+}
+
+func (m *readOnlyMode) set(ctx context.Context, enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.dbClient.UpdateReadOnlyEntry(ctx, db.ReadOnlyEntry{ReadOnly: enabled, UpdatedAt: time.Now().UTC()}); err != nil {
+		return err
+	}
+
+	m.enabled = enabled
+	m.expires = time.Now().Add(readOnlyCacheTTL)
+	m.version++
+	return nil
+}
+
+// Only one caller reads the db when the cached mode expires, the others are
+// served the cached mode meanwhile. The last known mode is kept when it can't
+// be read from the db.
+func (m *readOnlyMode) isEnabled() bool {
+	if m.forced {
+		return true
+	}
+
+	m.mu.Lock()
+	if m.refreshing || time.Now().Before(m.expires) {
+		defer m.mu.Unlock()
+		return m.enabled
+	}
+	m.refreshing = true
+	version := m.version
+	m.mu.Unlock()
+
+	// Not bound to the request, a cancelled request shouldn't fail the refresh
+	// for everyone else.
+	readCtx, cancel := context.WithTimeout(context.Background(), readOnlyReadTimeout)
+	defer cancel()
+	entry, err := m.dbClient.ReadReadOnlyEntry(readCtx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refreshing = false
+	if err != nil {
+		level.Error(m.logger).Log("message", "error reading read-only mode", "error", err)
+	} else if version == m.version {
+		m.enabled = entry.ReadOnly
+	}
+	if version == m.version {
+		m.expires = time.Now().Add(readOnlyCacheTTL)
+	}
+
+	return m.enabled
 }
 
 // Service HealthCheck
@@ -132,16 +226,17 @@ func (h handler) listWorkflows(w http.ResponseWriter, r *http.Request) {
 }
 
 // Creates workflow init params by pulling manifest from given git repo, commit sha, and code path
-func (h handler) loadCreateWorkflowRequestFromGit(repository, commitHash, path string) (requests.CreateWorkflow, error) {
+// The manifest contents are also returned.
+func (h handler) loadCreateWorkflowRequestFromGit(repository, commitHash, path string) (requests.CreateWorkflow, []byte, error) {
 	level.Debug(h.logger).Log("message", fmt.Sprintf("retrieving manifest from repository %s at sha %s with path %s", repository, commitHash, path))
 	fileContents, err := h.gitClient.GetManifestFile(repository, commitHash, path)
 	if err != nil {
-		return requests.CreateWorkflow{}, err
+		return requests.CreateWorkflow{}, nil, err
 	}
 
 	var cwr requests.CreateWorkflow
 	err = yaml.Unmarshal(fileContents, &cwr)
-	return cwr, err
+	return cwr, fileContents, err
 }
 
 func (h handler) createWorkflowFromGit(w http.ResponseWriter, r *http.Request) {
@@ -193,7 +288,7 @@ func (h handler) createWorkflowFromGit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cwr, err := h.loadCreateWorkflowRequestFromGit(projectEntry.Repository, cgwr.CommitHash, cgwr.Path)
+	cwr, manifest, err := h.loadCreateWorkflowRequestFromGit(projectEntry.Repository, cgwr.CommitHash, cgwr.Path)
 	if err != nil {
 		level.Error(l).Log("message", "error loading workflow data from git", "error", err)
 		h.errorResponse(w, "error loading workflow data from git", http.StatusInternalServerError)
@@ -203,7 +298,7 @@ func (h handler) createWorkflowFromGit(w http.ResponseWriter, r *http.Request) {
 	log.With(l, "project", cwr.ProjectName, "target", cwr.TargetName, "framework", cwr.Framework, "type", cwr.Type, "workflow-template", cwr.WorkflowTemplateName)
 
 	level.Debug(l).Log("message", "creating workflow")
-	h.createWorkflowFromRequest(ctx, w, r, a, cwr, l)
+	h.createWorkflowFromRequest(ctx, w, r, a, cwr, manifest, l)
 }
 
 // Creates a workflow
@@ -239,15 +334,26 @@ func (h handler) createWorkflow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Scanned indented so findings report a useful line, request bodies are
+	// commonly sent on a single line.
+	var manifest bytes.Buffer
+	if err := json.Indent(&manifest, reqBody, "", "  "); err != nil {
+		level.Error(l).Log("message", "error indenting workflow data", "error", err)
+		h.errorResponse(w, "error deserializing workflow data", http.StatusBadRequest)
+		return
+	}
+
 	log.With(l, "project", cwr.ProjectName, "target", cwr.TargetName, "framework", cwr.Framework, "type", cwr.Type, "workflow-template", cwr.WorkflowTemplateName)
 	level.Debug(l).Log("message", "creating workflow")
-	h.createWorkflowFromRequest(ctx, w, r, a, cwr, l)
+	h.createWorkflowFromRequest(ctx, w, r, a, cwr, manifest.Bytes(), l)
 }
 
 // Creates a workflow
-// Context is not currently used as Argo has its own and Vault doesn't
+// Context is only used for the db as Argo has its own and Vault doesn't
 // currently support it.
-func (h handler) createWorkflowFromRequest(_ context.Context, w http.ResponseWriter, r *http.Request, a *credentials.Authorization, cwr requests.CreateWorkflow, l log.Logger) {
+// The manifest is the content cwr was loaded from and is only used for
+// secret scanning.
+func (h handler) createWorkflowFromRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, a *credentials.Authorization, cwr requests.CreateWorkflow, manifest []byte, l log.Logger) {
 	types, err := h.config.listTypes(cwr.Framework)
 	if err != nil {
 		level.Error(l).Log("message", "error invalid framework", "error", err)
@@ -268,6 +374,27 @@ func (h handler) createWorkflowFromRequest(_ context.Context, w http.ResponseWri
 		return
 	}
 
+	var secretFindings []scanner.Finding
+	if mode := h.config.SecretScanning.Mode; mode != "" {
+		level.Debug(l).Log("message", "scanning manifest for secrets", "mode", mode)
+		secretFindings, err = h.secretScanner.Scan(manifest)
+		if err != nil {
+			level.Error(l).Log("message", "error scanning manifest for secrets", "error", err)
+			if mode == secretScanningModeBlock {
+				h.errorResponse(w, "error scanning manifest for secrets", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if len(secretFindings) > 0 {
+			level.Warn(l).Log("message", "potential secrets found in manifest", "findings", workflow.FormatSecretFindings(secretFindings))
+			if mode == secretScanningModeBlock {
+				h.errorResponse(w, fmt.Sprintf("invalid request, manifest contains potential secrets %s", formatSecretFindingsMessage(secretFindings)), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
 	workflowFrom := fmt.Sprintf("workflowtemplate/%s", cwr.WorkflowTemplateName)
 	executeContainerImageURI := cwr.Parameters["execute_container_image_uri"]
 	environmentVariablesString := generateEnvVariablesString(cwr.EnvironmentVariables)
@@ -294,14 +421,6 @@ func (h handler) createWorkflowFromRequest(_ context.Context, w http.ResponseWri
 		return
 	}
 
-	level.Debug(l).Log("message", "getting credentials provider token")
-	credentialsToken, err := cp.GetToken()
-	if err != nil {
-		level.Error(l).Log("message", "error getting credentials provider token", "error", err)
-		h.errorResponse(w, "error retrieving credentials provider token", http.StatusInternalServerError)
-		return
-	}
-
 	projectExists, err := cp.ProjectExists(cwr.ProjectName)
 	if err != nil {
 		level.Error(l).Log("message", "error checking project", "error", err)
@@ -315,25 +434,54 @@ func (h handler) createWorkflowFromRequest(_ context.Context, w http.ResponseWri
 		return
 	}
 
-	targetExists, err := cp.TargetExists(cwr.ProjectName, cwr.TargetName)
+	// Also checks the target exists.
+	credentialType, err := cp.GetTargetCredentialType(cwr.ProjectName, cwr.TargetName)
+	if errors.Is(err, credentials.ErrTargetNotFound) {
+		level.Error(l).Log("message", "target not found")
+		h.errorResponse(w, "target not found", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		level.Error(l).Log("message", "error retrieving target", "error", err)
+		level.Error(l).Log("message", "error retrieving target credential type", "error", err)
 		h.errorResponse(w, "error retrieving target", http.StatusInternalServerError)
 		return
 	}
-	if !targetExists {
-		level.Error(l).Log("message", "target not found")
-		h.errorResponse(w, "target not found", http.StatusBadRequest)
+
+	// Tokens issued for a project can read credentials for any of its
+	// targets, so this is only enforced for the target being operated on.
+	if !h.config.isCredentialTypeAllowed(cwr.ProjectName, credentialType) {
+		level.Error(l).Log("message", "credential type not allowed", "credential_type", credentialType)
+		h.errorResponse(w, fmt.Sprintf("invalid request, credential_type '%s' is not allowed for project", credentialType), http.StatusBadRequest)
+		return
+	}
+
+	level.Debug(l).Log("message", "getting credentials provider token")
+	credentialsToken, credentialsTokenAccessor, err := cp.GetToken()
+	if err != nil {
+		level.Error(l).Log("message", "error getting credentials provider token", "error", err)
+		h.errorResponse(w, "error retrieving credentials provider token", http.StatusInternalServerError)
 		return
 	}
 
 	level.Debug(l).Log("message", "creating workflow parameters")
 	parameters := workflow.NewParameters(environmentVariablesString, executeCommand, executeContainerImageURI, cwr.TargetName, cwr.ProjectName, cwr.Parameters, credentialsToken)
 
-	workflowLabels := map[string]string{txIDHeader: r.Header.Get(txIDHeader)}
+	workflowLabels := map[string]string{
+		txIDHeader:                  r.Header.Get(txIDHeader),
+		workflow.TokenAccessorLabel: credentialsTokenAccessor,
+	}
+
+	if timeout := h.config.getTimeout(cwr.Framework, cwr.ProjectName, cwr.TargetName); timeout > 0 {
+		workflowLabels[workflow.DeadlineLabel] = strconv.FormatInt(time.Now().Add(timeout).Unix(), 10)
+	}
+
+	workflowAnnotations := map[string]string{}
+	if len(secretFindings) > 0 {
+		workflowAnnotations[workflow.SecretFindingsAnnotation] = workflow.FormatSecretFindings(secretFindings)
+	}
 
 	level.Debug(l).Log("message", "creating workflow")
-	workflowName, err := h.argo.Submit(h.argoCtx, workflowFrom, parameters, workflowLabels)
+	workflowName, err := h.argo.Submit(h.argoCtx, workflowFrom, parameters, workflowLabels, workflowAnnotations)
 	if err != nil {
 		level.Error(l).Log("message", "error creating workflow", "error", err)
 		h.errorResponse(w, "error creating workflow", http.StatusInternalServerError)
@@ -342,11 +490,23 @@ func (h handler) createWorkflowFromRequest(_ context.Context, w http.ResponseWri
 
 	l = log.With(l, "workflow", workflowName)
 	level.Debug(l).Log("message", "workflow created")
+
+	// Used to find stale targets. The workflow is already created, so this
+	// doesn't fail the request.
+	level.Debug(l).Log("message", "recording target activity")
+	if err := h.dbClient.UpdateTargetActivityEntry(ctx, db.TargetActivityEntry{
+		ProjectID:       cwr.ProjectName,
+		TargetName:      cwr.TargetName,
+		LastSubmittedAt: time.Now().UTC(),
+	}); err != nil {
+		level.Error(l).Log("message", "error recording target activity", "error", err)
+	}
 	tokenHead := credentialsToken[0:8]
 
 	level.Info(l).Log("message", fmt.Sprintf("Received token '%s...'", tokenHead))
 	var cwresp workflow.CreateWorkflowResponse
 	cwresp.WorkflowName = workflowName
+	cwresp.SecretFindings = secretFindings
 	jsonData, err := json.Marshal(cwresp)
 	if err != nil {
 		level.Error(l).Log("message", "error serializing workflow response", "error", err)
@@ -732,6 +892,12 @@ func (h handler) createTarget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.config.isCredentialTypeAllowed(projectName, ctr.Properties.CredentialType) {
+		level.Error(l).Log("message", "credential type not allowed", "credential_type", ctr.Properties.CredentialType)
+		h.errorResponse(w, fmt.Sprintf("invalid request, credential_type must be one of '%s'", strings.Join(h.config.allowedCredentialTypes(projectName), " ")), http.StatusBadRequest)
+		return
+	}
+
 	l = log.With(l, "target", ctr.Name)
 
 	level.Debug(l).Log("message", "creating credential provider")
@@ -941,6 +1107,19 @@ func (h handler) updateTarget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var update types.Target
+	if err := json.Unmarshal(reqBody, &update); err != nil {
+		level.Error(l).Log("message", "error reading target properties data", "error", err)
+		h.errorResponse(w, "error reading target properties data", http.StatusInternalServerError)
+		return
+	}
+
+	// role_arn only applies to assumed roles, so don't keep the existing
+	// role_arn when the credential type changes.
+	if update.Properties.CredentialType != "" && update.Properties.CredentialType != target.Properties.CredentialType {
+		target.Properties.RoleArn = ""
+	}
+
 	// merge request data into existing target struct for update data
 	if err := json.Unmarshal(reqBody, &target); err != nil {
 		level.Error(l).Log("message", "error reading target properties data", "error", err)
@@ -957,6 +1136,12 @@ func (h handler) updateTarget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.config.isCredentialTypeAllowed(projectName, target.Properties.CredentialType) {
+		level.Error(l).Log("message", "credential type not allowed", "credential_type", target.Properties.CredentialType)
+		h.errorResponse(w, fmt.Sprintf("invalid request, credential_type must be one of '%s'", strings.Join(h.config.allowedCredentialTypes(projectName), " ")), http.StatusBadRequest)
+		return
+	}
+
 	level.Debug(l).Log("message", "updating target")
 	err = cp.UpdateTarget(projectName, target)
 	if err != nil {
@@ -975,6 +1160,80 @@ func (h handler) updateTarget(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, string(data))
 }
 
+// Gets the read-only mode
+func (h handler) getReadOnly(w http.ResponseWriter, r *http.Request) {
+	l := h.requestLogger(r, "op", "get-read-only")
+
+	level.Debug(l).Log("message", "validating authorization header for get read-only")
+	ah := r.Header.Get("Authorization")
+	a, err := credentials.NewAuthorization(ah)
+	if err != nil {
+		h.errorResponse(w, "error unauthorized, invalid authorization header format", http.StatusUnauthorized)
+		return
+	}
+	if err := a.Validate(a.ValidateAuthorizedAdmin(h.env.AdminSecret)); err != nil {
+		h.errorResponse(w, "error unauthorized, invalid authorization header", http.StatusUnauthorized)
+		return
+	}
+
+	h.readOnlyResponse(w, r, l)
+}
+
+// Sets the read-only mode
+func (h handler) setReadOnly(w http.ResponseWriter, r *http.Request) {
+	l := h.requestLogger(r, "op", "set-read-only")
+
+	level.Debug(l).Log("message", "validating authorization header for set read-only")
+	ah := r.Header.Get("Authorization")
+	a, err := credentials.NewAuthorization(ah)
+	if err != nil {
+		h.errorResponse(w, "error unauthorized, invalid authorization header format", http.StatusUnauthorized)
+		return
+	}
+	if err := a.Validate(a.ValidateAuthorizedAdmin(h.env.AdminSecret)); err != nil {
+		h.errorResponse(w, "error unauthorized, invalid authorization header", http.StatusUnauthorized)
+		return
+	}
+
+	var srr requests.SetReadOnly
+	reqBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		level.Error(l).Log("message", "error reading request body", "error", err)
+		h.errorResponse(w, "error reading request body", http.StatusInternalServerError)
+		return
+	}
+	if err := json.Unmarshal(reqBody, &srr); err != nil {
+		level.Error(l).Log("message", "error decoding request", "error", err)
+		h.errorResponse(w, "error decoding request", http.StatusBadRequest)
+		return
+	}
+	if err := srr.Validate(); err != nil {
+		level.Error(l).Log("message", "error invalid request", "error", err)
+		h.errorResponse(w, fmt.Sprintf("invalid request, %s", err), http.StatusBadRequest)
+		return
+	}
+
+	level.Info(l).Log("message", "setting read-only mode", "read-only", *srr.ReadOnly)
+	if err := h.readOnly.set(r.Context(), *srr.ReadOnly); err != nil {
+		level.Error(l).Log("message", "error setting read-only mode", "error", err)
+		h.errorResponse(w, "error setting read-only mode", http.StatusInternalServerError)
+		return
+	}
+
+	h.readOnlyResponse(w, r, l)
+}
+
+func (h handler) readOnlyResponse(w http.ResponseWriter, r *http.Request, l log.Logger) {
+	data, err := json.Marshal(responses.ReadOnly{ReadOnly: h.readOnly.isEnabled()})
+	if err != nil {
+		level.Error(l).Log("message", "error creating response", "error", err)
+		h.errorResponse(w, "error creating response object", http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprint(w, string(data))
+}
+
 // Convenience method that writes a failure response in a standard manner
 func (h handler) errorResponse(w http.ResponseWriter, message string, httpStatus int) {
 	r := generateErrorResponseJSON(message)
@@ -982,6 +1241,16 @@ func (h handler) errorResponse(w http.ResponseWriter, message string, httpStatus
 	fmt.Fprint(w, r)
 }
 
+// Formats findings for error responses, e.g.
+// "'aws-access-key-id' (line 2), 'private-key' (line 10)".
+func formatSecretFindingsMessage(findings []scanner.Finding) string {
+	formatted := make([]string, 0, len(findings))
+	for _, f := range findings {
+		formatted = append(formatted, fmt.Sprintf("'%s' (line %d)", f.RuleID, f.Line))
+	}
+	return strings.Join(formatted, ", ")
+}
+
 func generateEnvVariablesString(environmentVariables map[string]string) string {
 	if len(environmentVariables) == 0 {
 		return ""
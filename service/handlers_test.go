@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/cello-proj/cello/internal/responses"
 	"github.com/cello-proj/cello/internal/types"
@@ -19,6 +21,7 @@ import (
 	"github.com/cello-proj/cello/service/internal/db"
 	"github.com/cello-proj/cello/service/internal/env"
 	"github.com/cello-proj/cello/service/internal/git"
+	"github.com/cello-proj/cello/service/internal/scanner"
 	"github.com/cello-proj/cello/service/internal/workflow"
 
 	"github.com/go-kit/log"
@@ -59,6 +62,67 @@ func (d mockDB) DeleteProjectEntry(ctx context.Context, project string) error {
 	return nil
 }
 
+func (d mockDB) ListProjectEntries(ctx context.Context) ([]db.ProjectEntry, error) {
+	return []db.ProjectEntry{}, nil
+}
+
+func (d mockDB) CreateArchivedTargetEntry(ctx context.Context, ate db.ArchivedTargetEntry) error {
+	return nil
+}
+
+func (d mockDB) DeleteArchivedTargetEntry(ctx context.Context, ate db.ArchivedTargetEntry) error {
+	return nil
+}
+
+func (d mockDB) ReadReadOnlyEntry(ctx context.Context) (db.ReadOnlyEntry, error) {
+	return db.ReadOnlyEntry{}, nil
+}
+
+func (d mockDB) UpdateReadOnlyEntry(ctx context.Context, roe db.ReadOnlyEntry) error {
+	return nil
+}
+
+func (d mockDB) UpdateTargetActivityEntry(ctx context.Context, tae db.TargetActivityEntry) error {
+	return nil
+}
+
+func (d mockDB) ListTargetActivityEntries(ctx context.Context) ([]db.TargetActivityEntry, error) {
+	return []db.TargetActivityEntry{}, nil
+}
+
+// Holds the shared read-only mode.
+type mockReadOnlyDB struct {
+	mockDB
+	readOnly *bool
+	err      error
+}
+
+func (d mockReadOnlyDB) ReadReadOnlyEntry(ctx context.Context) (db.ReadOnlyEntry, error) {
+	return db.ReadOnlyEntry{ReadOnly: *d.readOnly}, d.err
+}
+
+func (d mockReadOnlyDB) UpdateReadOnlyEntry(ctx context.Context, roe db.ReadOnlyEntry) error {
+	if d.err != nil {
+		return d.err
+	}
+	*d.readOnly = roe.ReadOnly
+	return nil
+}
+
+// Blocks reads until released.
+type mockBlockingReadOnlyDB struct {
+	mockDB
+	readOnly bool
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (d mockBlockingReadOnlyDB) ReadReadOnlyEntry(ctx context.Context) (db.ReadOnlyEntry, error) {
+	d.started <- struct{}{}
+	<-d.release
+	return db.ReadOnlyEntry{ReadOnly: d.readOnly}, nil
+}
+
 type mockGitClient struct{}
 
 func newMockGitClient() git.Client {
@@ -93,18 +157,36 @@ func (m mockWorkflowSvc) List(ctx context.Context) ([]string, error) {
 	return []string{"project1-target1-abcde", "project2-target2-12345"}, nil
 }
 
-func (m mockWorkflowSvc) Submit(ctx context.Context, from string, parameters map[string]string, labels map[string]string) (string, error) {
+func (m mockWorkflowSvc) ListStatus(ctx context.Context, labelSelector string) ([]workflow.Status, error) {
+	return []workflow.Status{}, nil
+}
+
+func (m mockWorkflowSvc) Stop(ctx context.Context, workflowName string) error {
+	return nil
+}
+
+func (m mockWorkflowSvc) Submit(ctx context.Context, from string, parameters map[string]string, labels map[string]string, annotations map[string]string) (string, error) {
 	return "wf-123456", nil
 }
 
+type mockRoleChecker struct{}
+
+func (m mockRoleChecker) RoleExists(roleArn string) (bool, error) {
+	return true, nil
+}
+
 func newMockProvider(a credentials.Authorization, env env.Vars, h http.Header, f credentials.VaultConfigFn, fn credentials.VaultSvcFn) (credentials.Provider, error) {
 	return &mockCredentialsProvider{}, nil
 }
 
 type mockCredentialsProvider struct{}
 
-func (m mockCredentialsProvider) GetToken() (string, error) {
-	return testPassword, nil
+func (m mockCredentialsProvider) GetToken() (string, string, error) {
+	return testPassword, "tokenAccessor", nil
+}
+
+func (m mockCredentialsProvider) RevokeToken(accessor string) error {
+	return nil
 }
 
 func (m mockCredentialsProvider) CreateProject(name string) (string, string, error) {
@@ -147,6 +229,16 @@ func (m mockCredentialsProvider) GetTarget(project string, target string) (types
 	}, nil
 }
 
+func (m mockCredentialsProvider) GetTargetCredentialType(project string, target string) (string, error) {
+	switch target {
+	case "TARGET_EXISTS":
+		return "assumed_role", nil
+	case "IAM_USER_TARGET":
+		return "iam_user", nil
+	}
+	return "", credentials.ErrTargetNotFound
+}
+
 func (m mockCredentialsProvider) DeleteTarget(string, t string) error {
 	if t == "undeletabletarget" {
 		return fmt.Errorf("Some error occured deleting this target")
@@ -164,6 +256,7 @@ func (m mockCredentialsProvider) ListTargets(name string) ([]string, error) {
 func (m mockCredentialsProvider) ProjectExists(name string) (bool, error) {
 	existingProjects := []string{
 		"projectalreadyexists",
+		"grandfathered",
 		"undeletableprojecttargets",
 		"undeletableproject",
 		"somedeletedberror",
@@ -177,7 +270,7 @@ func (m mockCredentialsProvider) ProjectExists(name string) (bool, error) {
 }
 
 func (m mockCredentialsProvider) TargetExists(projectName, targetName string) (bool, error) {
-	if targetName == "TARGET_EXISTS" {
+	if targetName == "TARGET_EXISTS" || targetName == "IAM_USER_TARGET" {
 		return true, nil
 	}
 	return false, nil
@@ -196,6 +289,7 @@ type test struct {
 	authHeader string
 	url        string
 	method     string
+	readOnly   bool
 }
 
 func TestCreateProject(t *testing.T) {
@@ -394,6 +488,33 @@ func TestCreateTarget(t *testing.T) {
 			url:        "/projects/projectalreadyexists/targets",
 			method:     "POST",
 		},
+		{
+			name:       "credential type must be allowed",
+			req:        loadJSON(t, "TestCreateTarget/credential_type_must_be_allowed_request.json"),
+			want:       http.StatusBadRequest,
+			respFile:   "TestCreateTarget/credential_type_must_be_allowed_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects/projectalreadyexists/targets",
+			method:     "POST",
+		},
+		{
+			name:       "role_arn is only allowed for assumed role",
+			req:        loadJSON(t, "TestCreateTarget/role_arn_only_allowed_for_assumed_role_request.json"),
+			want:       http.StatusBadRequest,
+			respFile:   "TestCreateTarget/role_arn_only_allowed_for_assumed_role_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects/projectalreadyexists/targets",
+			method:     "POST",
+		},
+		{
+			name:       "can create target with credential type allowed for project",
+			req:        loadJSON(t, "TestCreateTarget/credential_type_must_be_allowed_request.json"),
+			want:       http.StatusOK,
+			respFile:   "TestCreateTarget/can_create_target_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects/grandfathered/targets",
+			method:     "POST",
+		},
 		{
 			name:       "fails to create target when not admin",
 			req:        loadJSON(t, "TestCreateTarget/fails_to_create_target_when_not_admin_request.json"),
@@ -515,6 +636,33 @@ func TestUpdateTarget(t *testing.T) {
 			url:        "/projects/projectalreadyexists/targets/TARGET_EXISTS",
 			method:     "PATCH",
 		},
+		{
+			name:       "credential type must be allowed",
+			req:        loadJSON(t, "TestUpdateTarget/credential_type_must_be_allowed_request.json"),
+			want:       http.StatusBadRequest,
+			respFile:   "TestUpdateTarget/credential_type_must_be_allowed_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects/projectalreadyexists/targets/TARGET_EXISTS",
+			method:     "PATCH",
+		},
+		{
+			name:       "can update credential type and clears role_arn",
+			req:        loadJSON(t, "TestUpdateTarget/can_update_credential_type_request.json"),
+			want:       http.StatusOK,
+			respFile:   "TestUpdateTarget/can_update_credential_type_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects/projectalreadyexists/targets/TARGET_EXISTS",
+			method:     "PATCH",
+		},
+		{
+			name:       "role_arn is only allowed for assumed role",
+			req:        loadJSON(t, "TestUpdateTarget/role_arn_only_allowed_for_assumed_role_request.json"),
+			want:       http.StatusBadRequest,
+			respFile:   "TestUpdateTarget/role_arn_only_allowed_for_assumed_role_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects/projectalreadyexists/targets/TARGET_EXISTS",
+			method:     "PATCH",
+		},
 		{
 			name:       "does not overwrite target name or type when in request",
 			req:        loadJSON(t, "TestUpdateTarget/fails_to_update_target_name_request.json"),
@@ -577,6 +725,15 @@ func TestCreateWorkflow(t *testing.T) {
 			method:     "POST",
 			url:        "/workflows",
 		},
+		{
+			name:       "target credential type must be allowed",
+			respFile:   "TestCreateWorkflow/target_credential_type_must_be_allowed_response.json",
+			req:        loadJSON(t, "TestCreateWorkflow/target_credential_type_must_be_allowed_request.json"),
+			authHeader: userAuthHeader,
+			want:       http.StatusBadRequest,
+			method:     "POST",
+			url:        "/workflows",
+		},
 		{
 			name:       "project must exist",
 			req:        loadJSON(t, "TestCreateWorkflow/project_must_exist.json"),
@@ -606,6 +763,133 @@ func TestCreateWorkflow(t *testing.T) {
 	runTests(t, tests)
 }
 
+type mockSubmitWorkflowSvc struct {
+	mockWorkflowSvc
+	annotations *map[string]string
+}
+
+func (m mockSubmitWorkflowSvc) Submit(ctx context.Context, from string, parameters map[string]string, labels map[string]string, annotations map[string]string) (string, error) {
+	*m.annotations = annotations
+	return "wf-123456", nil
+}
+
+type mockErrScanner struct{}
+
+func (m mockErrScanner) Scan(content []byte) ([]scanner.Finding, error) {
+	return nil, errors.New("scan error")
+}
+
+func TestCreateWorkflowSecretScanning(t *testing.T) {
+	tests := []struct {
+		name            string
+		mode            string
+		scanner         scanner.Scanner
+		req             string
+		want            int
+		respFile        string
+		body            string
+		wantAnnotations map[string]string
+	}{
+		{
+			name:     "block mode rejects manifest with secrets",
+			mode:     secretScanningModeBlock,
+			scanner:  scanner.NewRegexScanner(scanner.DefaultRules...),
+			req:      "TestCreateWorkflow/manifest_must_not_contain_secrets_request.json",
+			want:     http.StatusBadRequest,
+			respFile: "TestCreateWorkflow/manifest_must_not_contain_secrets_response.json",
+		},
+		{
+			name:            "block mode allows manifest without secrets",
+			mode:            secretScanningModeBlock,
+			scanner:         scanner.NewRegexScanner(scanner.DefaultRules...),
+			req:             "TestCreateWorkflow/can_create_workflow_request.json",
+			want:            http.StatusOK,
+			respFile:        "TestCreateWorkflow/can_create_workflow_response.json",
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name:    "warn mode returns secret findings",
+			mode:    secretScanningModeWarn,
+			scanner: scanner.NewRegexScanner(scanner.DefaultRules...),
+			req:     "TestCreateWorkflow/manifest_must_not_contain_secrets_request.json",
+			want:    http.StatusOK,
+			body:    `{"workflow_name":"wf-123456","secret_findings":[{"rule_id":"aws-access-key-id","line":8}]}`,
+			wantAnnotations: map[string]string{
+				workflow.SecretFindingsAnnotation: "aws-access-key-id:8",
+			},
+		},
+		{
+			name:            "manifest is not scanned when disabled",
+			scanner:         scanner.NewRegexScanner(scanner.DefaultRules...),
+			req:             "TestCreateWorkflow/manifest_must_not_contain_secrets_request.json",
+			want:            http.StatusOK,
+			respFile:        "TestCreateWorkflow/can_create_workflow_response.json",
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name:    "block mode fails on scanner error",
+			mode:    secretScanningModeBlock,
+			scanner: mockErrScanner{},
+			req:     "TestCreateWorkflow/can_create_workflow_request.json",
+			want:    http.StatusInternalServerError,
+			body:    `{"error_message":"error scanning manifest for secrets"}`,
+		},
+		{
+			name:            "warn mode continues on scanner error",
+			mode:            secretScanningModeWarn,
+			scanner:         mockErrScanner{},
+			req:             "TestCreateWorkflow/can_create_workflow_request.json",
+			want:            http.StatusOK,
+			respFile:        "TestCreateWorkflow/can_create_workflow_response.json",
+			wantAnnotations: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := loadConfig(testConfigPath)
+			if err != nil {
+				t.Fatalf("Unable to load config %s", err)
+			}
+			config.SecretScanning.Mode = tt.mode
+
+			var annotations map[string]string
+			h := handler{
+				logger:                 log.NewNopLogger(),
+				newCredentialsProvider: newMockProvider,
+				argo:                   mockSubmitWorkflowSvc{annotations: &annotations},
+				argoCtx:                context.Background(),
+				config:                 config,
+				env: env.Vars{
+					AdminSecret: testPassword,
+				},
+				dbClient:      newMockDB(),
+				readOnly:      newReadOnlyMode(false, newMockDB(), log.NewNopLogger()),
+				secretScanner: tt.scanner,
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/workflows", serialize(loadJSON(t, tt.req)))
+			req.Header.Add("Authorization", userAuthHeader)
+			w := httptest.NewRecorder()
+
+			setupRouter(h).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+
+			wantBody := tt.body
+			if tt.respFile != "" {
+				data, err := loadFileBytes(tt.respFile)
+				if err != nil {
+					t.Fatalf("unable to read response file '%s', err: '%s'", tt.respFile, err)
+				}
+				wantBody = string(data)
+			}
+			assert.JSONEq(t, wantBody, w.Body.String())
+			assert.Equal(t, tt.wantAnnotations, annotations)
+		})
+	}
+}
+
 func TestCreateWorkflowFromGit(t *testing.T) {
 	tests := []test{
 		{
@@ -789,11 +1073,226 @@ func serialize(toMarshal interface{}) *bytes.Buffer {
 	return bytes.NewBuffer(jsonStr)
 }
 
+func TestReadOnly(t *testing.T) {
+	tests := []test{
+		{
+			name:       "can list targets when read-only",
+			want:       http.StatusOK,
+			respFile:   "TestListTargets/can_get_target_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects/undeletableprojecttargets/targets",
+			method:     "GET",
+			readOnly:   true,
+		},
+		{
+			name:       "fails to create project when read-only",
+			req:        loadJSON(t, "TestCreateProject/can_create_project_request.json"),
+			want:       http.StatusServiceUnavailable,
+			respFile:   "TestReadOnly/service_is_read_only_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects",
+			method:     "POST",
+			readOnly:   true,
+		},
+		{
+			name:       "fails to delete target when read-only",
+			want:       http.StatusServiceUnavailable,
+			respFile:   "TestReadOnly/service_is_read_only_response.json",
+			authHeader: adminAuthHeader,
+			url:        "/projects/projectalreadyexists/targets/TARGET_EXISTS",
+			method:     "DELETE",
+			readOnly:   true,
+		},
+		{
+			name:       "fails to create workflow when read-only",
+			req:        loadJSON(t, "TestCreateWorkflow/can_create_workflow_request.json"),
+			want:       http.StatusServiceUnavailable,
+			respFile:   "TestReadOnly/service_is_read_only_response.json",
+			authHeader: userAuthHeader,
+			url:        "/workflows",
+			method:     "POST",
+			readOnly:   true,
+		},
+		{
+			name:       "can get read-only",
+			want:       http.StatusOK,
+			body:       `{"read_only":true}`,
+			authHeader: adminAuthHeader,
+			url:        "/admin/read-only",
+			method:     "GET",
+			readOnly:   true,
+		},
+		{
+			name:       "can disable read-only",
+			req:        map[string]interface{}{"read_only": false},
+			want:       http.StatusOK,
+			body:       `{"read_only":false}`,
+			authHeader: adminAuthHeader,
+			url:        "/admin/read-only",
+			method:     "PUT",
+			readOnly:   true,
+		},
+		{
+			name:       "can enable read-only",
+			req:        map[string]interface{}{"read_only": true},
+			want:       http.StatusOK,
+			body:       `{"read_only":true}`,
+			authHeader: adminAuthHeader,
+			url:        "/admin/read-only",
+			method:     "PUT",
+		},
+		{
+			name:       "fails to set read-only when not admin",
+			req:        map[string]interface{}{"read_only": true},
+			want:       http.StatusUnauthorized,
+			authHeader: userAuthHeader,
+			url:        "/admin/read-only",
+			method:     "PUT",
+		},
+		{
+			name:       "fails to set read-only when missing value",
+			req:        map[string]interface{}{},
+			want:       http.StatusBadRequest,
+			authHeader: adminAuthHeader,
+			url:        "/admin/read-only",
+			method:     "PUT",
+		},
+	}
+	runTests(t, tests)
+}
+
+func TestStaleTargets(t *testing.T) {
+	tests := []test{
+		{
+			name:       "fails to get stale targets when not admin",
+			want:       http.StatusUnauthorized,
+			authHeader: userAuthHeader,
+			url:        "/admin/stale-targets?days=90",
+			method:     "GET",
+		},
+		{
+			name:       "fails to get stale targets without days",
+			want:       http.StatusBadRequest,
+			body:       `{"error_message":"invalid request, days must be a positive integer"}`,
+			authHeader: adminAuthHeader,
+			url:        "/admin/stale-targets",
+			method:     "GET",
+		},
+		{
+			name:       "fails to get stale targets with invalid days",
+			want:       http.StatusBadRequest,
+			body:       `{"error_message":"invalid request, days must be a positive integer"}`,
+			authHeader: adminAuthHeader,
+			url:        "/admin/stale-targets?days=0",
+			method:     "GET",
+		},
+		{
+			name:       "fails to archive stale targets when not admin",
+			req:        map[string]interface{}{"days": 90, "targets": []map[string]string{{"project_name": "project1", "target_name": "target1"}}},
+			want:       http.StatusUnauthorized,
+			authHeader: userAuthHeader,
+			url:        "/admin/stale-targets/archive",
+			method:     "POST",
+		},
+		{
+			name:       "fails to archive stale targets without targets",
+			req:        map[string]interface{}{"days": 90},
+			want:       http.StatusBadRequest,
+			body:       `{"error_message":"invalid request, targets is required"}`,
+			authHeader: adminAuthHeader,
+			url:        "/admin/stale-targets/archive",
+			method:     "POST",
+		},
+	}
+	runTests(t, tests)
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	t.Run("reads shared mode", func(t *testing.T) {
+		shared := true
+		m := newReadOnlyMode(false, mockReadOnlyDB{readOnly: &shared}, log.NewNopLogger())
+		assert.True(t, m.isEnabled())
+	})
+
+	t.Run("caches shared mode", func(t *testing.T) {
+		shared := false
+		m := newReadOnlyMode(false, mockReadOnlyDB{readOnly: &shared}, log.NewNopLogger())
+		assert.False(t, m.isEnabled())
+
+		shared = true
+		assert.False(t, m.isEnabled())
+
+		m.expires = time.Now()
+		assert.True(t, m.isEnabled())
+	})
+
+	t.Run("keeps last mode on db error", func(t *testing.T) {
+		shared := true
+		m := newReadOnlyMode(false, mockReadOnlyDB{readOnly: &shared}, log.NewNopLogger())
+		assert.True(t, m.isEnabled())
+
+		m.dbClient = mockReadOnlyDB{readOnly: &shared, err: errors.New("db error")}
+		m.expires = time.Now()
+		assert.True(t, m.isEnabled())
+	})
+
+	t.Run("serves cached mode while refreshing", func(t *testing.T) {
+		blocking := mockBlockingReadOnlyDB{readOnly: true, started: make(chan struct{}), release: make(chan struct{})}
+		m := newReadOnlyMode(false, blocking, log.NewNopLogger())
+
+		refreshed := make(chan bool)
+		go func() { refreshed <- m.isEnabled() }()
+		<-blocking.started
+
+		assert.False(t, m.isEnabled())
+
+		close(blocking.release)
+		assert.True(t, <-refreshed)
+		assert.True(t, m.isEnabled())
+	})
+
+	t.Run("refresh doesn't overwrite set", func(t *testing.T) {
+		blocking := mockBlockingReadOnlyDB{readOnly: true, started: make(chan struct{}), release: make(chan struct{})}
+		m := newReadOnlyMode(false, blocking, log.NewNopLogger())
+
+		refreshed := make(chan bool)
+		go func() { refreshed <- m.isEnabled() }()
+		<-blocking.started
+
+		assert.NoError(t, m.set(context.Background(), false))
+
+		close(blocking.release)
+		assert.False(t, <-refreshed)
+		assert.False(t, m.isEnabled())
+	})
+
+	t.Run("set updates shared mode", func(t *testing.T) {
+		shared := false
+		m := newReadOnlyMode(false, mockReadOnlyDB{readOnly: &shared}, log.NewNopLogger())
+		assert.NoError(t, m.set(context.Background(), true))
+		assert.True(t, shared)
+		assert.True(t, m.isEnabled())
+	})
+
+	t.Run("set fails on db error", func(t *testing.T) {
+		shared := false
+		m := newReadOnlyMode(false, mockReadOnlyDB{readOnly: &shared, err: errors.New("db error")}, log.NewNopLogger())
+		assert.Error(t, m.set(context.Background(), true))
+		assert.False(t, m.isEnabled())
+	})
+
+	t.Run("forced by environment", func(t *testing.T) {
+		shared := false
+		m := newReadOnlyMode(true, mockReadOnlyDB{readOnly: &shared}, log.NewNopLogger())
+		assert.True(t, m.isEnabled())
+	})
+}
+
 // Run tests, checking the response status codes.
 func runTests(t *testing.T, tests []test) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp := executeRequest(tt.method, tt.url, serialize(tt.req), tt.authHeader)
+			resp := executeRequest(tt.method, tt.url, serialize(tt.req), tt.authHeader, tt.readOnly)
 			if resp.StatusCode != tt.want {
 				t.Errorf("Unexpected status code %d", resp.StatusCode)
 			}
@@ -827,7 +1326,7 @@ func runTests(t *testing.T, tests []test) {
 }
 
 // Execute a generic HTTP request, making sure to add the appropriate authorization header.
-func executeRequest(method string, url string, body *bytes.Buffer, authHeader string) *http.Response {
+func executeRequest(method string, url string, body *bytes.Buffer, authHeader string, readOnly bool) *http.Response {
 	config, err := loadConfig(testConfigPath)
 	if err != nil {
 		panic(fmt.Sprintf("Unable to load config %s", err))
@@ -843,7 +1342,10 @@ func executeRequest(method string, url string, body *bytes.Buffer, authHeader st
 		env: env.Vars{
 			AdminSecret: testPassword,
 		},
-		dbClient: newMockDB(),
+		dbClient:      newMockDB(),
+		readOnly:      newReadOnlyMode(false, mockReadOnlyDB{readOnly: &readOnly}, log.NewNopLogger()),
+		secretScanner: scanner.NewRegexScanner(scanner.DefaultRules...),
+		roleChecker:   mockRoleChecker{},
 	}
 
 	var router = setupRouter(h)
@@ -27,9 +27,11 @@ type Provider interface {
 	DeleteTarget(string, string) error
 	GetProject(string) (responses.GetProject, error)
 	GetTarget(string, string) (types.Target, error)
-	GetToken() (string, error)
+	GetTargetCredentialType(string, string) (string, error)
+	GetToken() (string, string, error)
 	ListTargets(string) ([]string, error)
 	ProjectExists(string) (bool, error)
+	RevokeToken(string) error
 	TargetExists(string, string) (bool, error)
 }
 
@@ -164,6 +166,16 @@ func (a Authorization) ValidateAuthorizedAdmin(adminSecret string) func() error
 	}
 }
 
+// NewAdminAuthorization provides an admin Authorization for use by the
+// service itself, such as when cleaning up after timed out workflows.
+func NewAdminAuthorization(adminSecret string) Authorization {
+	return Authorization{
+		Provider: "vault",
+		Key:      authorizationKeyAdmin,
+		Secret:   adminSecret,
+	}
+}
+
 // NewAuthorization provides an Authorization from a header.
 // This is separate from admin functions which use the admin env var
 func NewAuthorization(authorizationHeader string) (*Authorization, error) {
@@ -222,16 +234,26 @@ func (v VaultProvider) CreateTarget(projectName string, target types.Target) err
 		return errors.New("admin credentials must be used to create target")
 	}
 
-	options := map[string]interface{}{
+	path := fmt.Sprintf("aws/roles/%s-%s-target-%s", vaultProjectPrefix, projectName, target.Name)
+	_, err := v.vaultLogicalSvc.Write(path, targetOptions(target))
+	return err
+}
+
+// Vault only accepts role_arns for assumed roles. Writes are merged into the
+// existing role, so role_arns is always sent to clear it when the credential
+// type changes.
+func targetOptions(target types.Target) map[string]interface{} {
+	roleArns := []string{}
+	if target.Properties.RoleArn != "" {
+		roleArns = []string{target.Properties.RoleArn}
+	}
+
+	return map[string]interface{}{
 		"credential_type": target.Properties.CredentialType,
 		"policy_arns":     target.Properties.PolicyArns,
 		"policy_document": target.Properties.PolicyDocument,
-		"role_arns":       target.Properties.RoleArn,
+		"role_arns":       roleArns,
 	}
-
-	path := fmt.Sprintf("aws/roles/%s-%s-target-%s", vaultProjectPrefix, projectName, target.Name)
-	_, err := v.vaultLogicalSvc.Write(path, options)
-	return err
 }
 
 func defaultVaultReadonlyPolicyAWS(projectName string) string {
@@ -296,6 +318,23 @@ func (v VaultProvider) GetTarget(projectName, targetName string) (types.Target,
 		return types.Target{}, errors.New("admin credentials must be used to get target information")
 	}
 
+	return v.readTarget(projectName, targetName)
+}
+
+// GetTargetCredentialType returns the credential type of the target. Unlike
+// GetTarget, project credentials may be used so the credential type can be
+// checked before issuing a token. Returns ErrTargetNotFound when the target
+// doesn't exist.
+func (v VaultProvider) GetTargetCredentialType(projectName, targetName string) (string, error) {
+	target, err := v.readTarget(projectName, targetName)
+	if err != nil {
+		return "", err
+	}
+
+	return target.Properties.CredentialType, nil
+}
+
+func (v VaultProvider) readTarget(projectName, targetName string) (types.Target, error) {
 	sec, err := v.vaultLogicalSvc.Read(fmt.Sprintf("aws/roles/argo-cloudops-projects-%s-target-%s", projectName, targetName))
 	if err != nil {
 		return types.Target{}, fmt.Errorf("vault get target error: %w", err)
@@ -305,10 +344,15 @@ func (v VaultProvider) GetTarget(projectName, targetName string) (types.Target,
 		return types.Target{}, ErrTargetNotFound
 	}
 
-	// These should always exist.
-	roleArn := sec.Data["role_arns"].([]interface{})[0].(string)
+	// This should always exist.
 	credentialType := sec.Data["credential_type"].(string)
 
+	// Only exists for assumed roles.
+	var roleArn string
+	if val, ok := sec.Data["role_arns"].([]interface{}); ok && len(val) > 0 {
+		roleArn = val[0].(string)
+	}
+
 	// Optional.
 	policies := []string{}
 	if val, ok := sec.Data["policy_arns"]; ok {
@@ -336,9 +380,10 @@ func (v VaultProvider) GetTarget(projectName, targetName string) (types.Target,
 	}, nil
 }
 
-func (v VaultProvider) GetToken() (string, error) {
+// GetToken returns a new token and its accessor.
+func (v VaultProvider) GetToken() (string, string, error) {
 	if v.isAdmin() {
-		return "", errors.New("admin credentials cannot be used to get tokens")
+		return "", "", errors.New("admin credentials cannot be used to get tokens")
 	}
 
 	options := map[string]interface{}{
@@ -349,10 +394,10 @@ func (v VaultProvider) GetToken() (string, error) {
 	sec, err := v.vaultLogicalSvc.Write("auth/approle/login", options)
 	if err != nil {
 		fmt.Println(err.Error())
-		return "", err
+		return "", "", err
 	}
 
-	return sec.Auth.ClientToken, nil
+	return sec.Auth.ClientToken, sec.Auth.Accessor, nil
 }
 
 // TODO See if this can be removed when refactoring auth.
@@ -417,6 +462,29 @@ func (v VaultProvider) readSecretID(appRoleName string) (string, error) {
 	return secret.Data["secret_id"].(string), nil
 }
 
+// RevokeToken revokes the token with the given accessor along with any
+// credentials leased by it. AWS STS credentials are not leased, so those
+// already issued remain valid until they expire. Revoking a token which no
+// longer exists succeeds.
+func (v VaultProvider) RevokeToken(accessor string) error {
+	if !v.isAdmin() {
+		return errors.New("admin credentials must be used to revoke tokens")
+	}
+
+	options := map[string]interface{}{
+		"accessor": accessor,
+	}
+
+	if _, err := v.vaultLogicalSvc.Write("auth/token/revoke-accessor", options); err != nil {
+		var respErr *vault.ResponseError
+		if errors.As(err, &respErr) && strings.Contains(respErr.Error(), "invalid accessor") {
+			return nil
+		}
+		return fmt.Errorf("vault revoke token error: %w", err)
+	}
+	return nil
+}
+
 func (v VaultProvider) TargetExists(projectName, targetName string) (bool, error) {
 	_, err := v.GetTarget(projectName, targetName)
 	return !errors.Is(err, ErrTargetNotFound), nil
@@ -428,15 +496,8 @@ func (v VaultProvider) UpdateTarget(projectName string, target types.Target) err
 		return errors.New("admin credentials must be used to update target")
 	}
 
-	options := map[string]interface{}{
-		"credential_type": target.Properties.CredentialType,
-		"policy_arns":     target.Properties.PolicyArns,
-		"policy_document": target.Properties.PolicyDocument,
-		"role_arns":       target.Properties.RoleArn,
-	}
-
 	path := fmt.Sprintf("aws/roles/%s-%s-target-%s", vaultProjectPrefix, projectName, target.Name)
-	_, err := v.vaultLogicalSvc.Write(path, options)
+	_, err := v.vaultLogicalSvc.Write(path, targetOptions(target))
 	return err
 }
 
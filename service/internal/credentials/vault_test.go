@@ -79,15 +79,46 @@ func TestVaultCreateProject(t *testing.T) {
 
 func TestVaultCreateTarget(t *testing.T) {
 	tests := []struct {
-		name      string
-		admin     bool
-		vaultErr  error
-		errResult bool
+		name        string
+		admin       bool
+		target      types.Target
+		vaultErr    error
+		errResult   bool
+		wantOptions map[string]interface{}
 	}{
 		{
 			name:  "create target success",
 			admin: true,
 		},
+		{
+			name:  "create assumed role target sends role arn",
+			admin: true,
+			target: types.Target{Properties: types.TargetProperties{
+				CredentialType: "assumed_role",
+				PolicyArns:     []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"},
+				RoleArn:        "arn:aws:iam::012345678901:role/test",
+			}},
+			wantOptions: map[string]interface{}{
+				"credential_type": "assumed_role",
+				"policy_arns":     []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"},
+				"policy_document": "",
+				"role_arns":       []string{"arn:aws:iam::012345678901:role/test"},
+			},
+		},
+		{
+			name:  "create iam user target clears role arns",
+			admin: true,
+			target: types.Target{Properties: types.TargetProperties{
+				CredentialType: "iam_user",
+				PolicyArns:     []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"},
+			}},
+			wantOptions: map[string]interface{}{
+				"credential_type": "iam_user",
+				"policy_arns":     []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"},
+				"policy_document": "",
+				"role_arns":       []string{},
+			},
+		},
 		{
 			name:      "create target admin error",
 			admin:     false,
@@ -108,12 +139,13 @@ func TestVaultCreateTarget(t *testing.T) {
 			if tt.admin {
 				role = authorizationKeyAdmin
 			}
+			var written map[string]interface{}
 			v := VaultProvider{
 				roleID:          role,
-				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr},
+				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr, written: &written},
 			}
 
-			err := v.CreateTarget("test", types.Target{})
+			err := v.CreateTarget("test", tt.target)
 			if err != nil {
 				if !tt.errResult {
 					t.Errorf("\ndid not expect error, got: %v", err)
@@ -122,6 +154,9 @@ func TestVaultCreateTarget(t *testing.T) {
 				if tt.errResult {
 					t.Errorf("\nexpected error")
 				}
+				if tt.wantOptions != nil && !cmp.Equal(written, tt.wantOptions) {
+					t.Errorf("\nwant: %v\n got: %v", tt.wantOptions, written)
+				}
 			}
 		})
 	}
@@ -129,15 +164,46 @@ func TestVaultCreateTarget(t *testing.T) {
 
 func TestVaultUpdateTarget(t *testing.T) {
 	tests := []struct {
-		name      string
-		admin     bool
-		vaultErr  error
-		errResult bool
+		name        string
+		admin       bool
+		target      types.Target
+		vaultErr    error
+		errResult   bool
+		wantOptions map[string]interface{}
 	}{
 		{
 			name:  "update target success",
 			admin: true,
 		},
+		{
+			name:  "update assumed role target sends role arn",
+			admin: true,
+			target: types.Target{Properties: types.TargetProperties{
+				CredentialType: "assumed_role",
+				PolicyArns:     []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"},
+				RoleArn:        "arn:aws:iam::012345678901:role/test",
+			}},
+			wantOptions: map[string]interface{}{
+				"credential_type": "assumed_role",
+				"policy_arns":     []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"},
+				"policy_document": "",
+				"role_arns":       []string{"arn:aws:iam::012345678901:role/test"},
+			},
+		},
+		{
+			name:  "update iam user target clears role arns",
+			admin: true,
+			target: types.Target{Properties: types.TargetProperties{
+				CredentialType: "iam_user",
+				PolicyArns:     []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"},
+			}},
+			wantOptions: map[string]interface{}{
+				"credential_type": "iam_user",
+				"policy_arns":     []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"},
+				"policy_document": "",
+				"role_arns":       []string{},
+			},
+		},
 		{
 			name:      "update target admin error",
 			admin:     false,
@@ -158,12 +224,13 @@ func TestVaultUpdateTarget(t *testing.T) {
 			if tt.admin {
 				role = authorizationKeyAdmin
 			}
+			var written map[string]interface{}
 			v := VaultProvider{
 				roleID:          role,
-				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr},
+				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr, written: &written},
 			}
 
-			err := v.UpdateTarget("test", types.Target{})
+			err := v.UpdateTarget("test", tt.target)
 			if err != nil {
 				if !tt.errResult {
 					t.Errorf("\ndid not expect error, got: %v", err)
@@ -172,6 +239,9 @@ func TestVaultUpdateTarget(t *testing.T) {
 				if tt.errResult {
 					t.Errorf("\nexpected error")
 				}
+				if tt.wantOptions != nil && !cmp.Equal(written, tt.wantOptions) {
+					t.Errorf("\nwant: %v\n got: %v", tt.wantOptions, written)
+				}
 			}
 		})
 	}
@@ -289,6 +359,7 @@ func TestVaultGetTarget(t *testing.T) {
 	tests := []struct {
 		name      string
 		admin     bool
+		data      map[string]interface{}
 		vaultErr  error
 		errResult bool
 	}{
@@ -296,6 +367,14 @@ func TestVaultGetTarget(t *testing.T) {
 			name:  "get target success",
 			admin: true,
 		},
+		{
+			name:  "get target without role arns success",
+			admin: true,
+			data: map[string]interface{}{
+				"role_arns":       []interface{}{},
+				"credential_type": "iam_user",
+			},
+		},
 		{
 			name:      "get target admin error",
 			admin:     false,
@@ -316,14 +395,18 @@ func TestVaultGetTarget(t *testing.T) {
 			if tt.admin {
 				role = authorizationKeyAdmin
 			}
-			v := VaultProvider{
-				roleID: role,
-				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr, data: map[string]interface{}{
+			data := tt.data
+			if data == nil {
+				data = map[string]interface{}{
 					"role_arns":       []interface{}{"test-role-arn"},
 					"policy_arns":     []interface{}{"test-policy-arn"},
 					"policy_document": `{ "Version": "2012-10-17", "Statement": [ { "Effect": "Allow", "Action": "s3:ListBuckets", "Resource": "*" } ] }`,
 					"credential_type": "test-cred-type",
-				}},
+				}
+			}
+			v := VaultProvider{
+				roleID:          role,
+				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr, data: data},
 			}
 
 			_, err := v.GetTarget("testProject", "testTarget")
@@ -340,17 +423,73 @@ func TestVaultGetTarget(t *testing.T) {
 	}
 }
 
+func TestVaultGetTargetCredentialType(t *testing.T) {
+	tests := []struct {
+		name      string
+		admin     bool
+		vaultErr  error
+		want      string
+		errResult bool
+	}{
+		{
+			name: "get target credential type success",
+			want: "iam_user",
+		},
+		{
+			name:  "get target credential type as admin success",
+			admin: true,
+			want:  "iam_user",
+		},
+		{
+			name:      "get target credential type error",
+			vaultErr:  errTest,
+			errResult: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var role = "testRole"
+			if tt.admin {
+				role = authorizationKeyAdmin
+			}
+			v := VaultProvider{
+				roleID: role,
+				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr, data: map[string]interface{}{
+					"credential_type": "iam_user",
+				}},
+			}
+
+			credentialType, err := v.GetTargetCredentialType("testProject", "testTarget")
+			if err != nil {
+				if !tt.errResult {
+					t.Errorf("\ndid not expect error, got: %v", err)
+				}
+			} else {
+				if tt.errResult {
+					t.Errorf("\nexpected error")
+				}
+				if credentialType != tt.want {
+					t.Errorf("\nwant: %v\n got: %v", tt.want, credentialType)
+				}
+			}
+		})
+	}
+}
+
 func TestVaultGetToken(t *testing.T) {
 	tests := []struct {
 		name      string
 		token     string
+		accessor  string
 		admin     bool
 		vaultErr  error
 		errResult bool
 	}{
 		{
-			name:  "get token success",
-			token: "secretToken",
+			name:     "get token success",
+			token:    "secretToken",
+			accessor: "tokenAccessor",
 		},
 		{
 			name:      "get token admin error",
@@ -373,10 +512,10 @@ func TestVaultGetToken(t *testing.T) {
 			}
 			v := VaultProvider{
 				roleID:          role,
-				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr, token: tt.token},
+				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr, token: tt.token, accessor: tt.accessor},
 			}
 
-			token, err := v.GetToken()
+			token, accessor, err := v.GetToken()
 			if err != nil {
 				if !tt.errResult {
 					t.Errorf("\ndid not expect error, got: %v", err)
@@ -388,6 +527,64 @@ func TestVaultGetToken(t *testing.T) {
 				if !cmp.Equal(token, tt.token) {
 					t.Errorf("\nwant: %v\n got: %v", tt.token, token)
 				}
+				if !cmp.Equal(accessor, tt.accessor) {
+					t.Errorf("\nwant: %v\n got: %v", tt.accessor, accessor)
+				}
+			}
+		})
+	}
+}
+
+func TestVaultRevokeToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		admin     bool
+		vaultErr  error
+		errResult bool
+	}{
+		{
+			name:  "revoke token success",
+			admin: true,
+		},
+		{
+			name:      "revoke token admin error",
+			admin:     false,
+			errResult: true,
+		},
+		{
+			name:     "revoke token already revoked success",
+			admin:    true,
+			vaultErr: &vault.ResponseError{StatusCode: 400, Errors: []string{"invalid accessor"}},
+		},
+		{
+			name:      "revoke token error",
+			admin:     true,
+			vaultErr:  errTest,
+			errResult: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var role = "testRole"
+			if tt.admin {
+				role = authorizationKeyAdmin
+			}
+			v := VaultProvider{
+				roleID:          role,
+				vaultLogicalSvc: &mockVaultLogical{err: tt.vaultErr},
+			}
+
+			err := v.RevokeToken("tokenAccessor")
+			if err != nil {
+				if !tt.errResult {
+					t.Errorf("\ndid not expect error, got: %v", err)
+				}
+			} else {
+				if tt.errResult {
+					t.Errorf("\nexpected error")
+				}
 			}
 		})
 	}
@@ -596,9 +793,12 @@ func TestNewAuthorization(t *testing.T) {
 
 type mockVaultLogical struct {
 	vault.Logical
-	data  map[string]interface{}
-	token string
-	err   error
+	data     map[string]interface{}
+	token    string
+	accessor string
+	err      error
+	// Holds the data of the last write when set.
+	written *map[string]interface{}
 }
 
 func (m mockVaultLogical) Read(path string) (*vault.Secret, error) {
@@ -619,7 +819,10 @@ func (m mockVaultLogical) Write(path string, data map[string]interface{}) (*vaul
 	if m.err != nil {
 		return nil, m.err
 	}
-	return &vault.Secret{Data: m.data, Auth: &vault.SecretAuth{ClientToken: m.token}}, nil
+	if m.written != nil {
+		*m.written = data
+	}
+	return &vault.Secret{Data: m.data, Auth: &vault.SecretAuth{ClientToken: m.token, Accessor: m.accessor}}, nil
 }
 
 func (m mockVaultLogical) Delete(path string) (*vault.Secret, error) {
@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/upper/db/v4"
 	"github.com/upper/db/v4/adapter/postgresql"
@@ -12,11 +14,42 @@ type ProjectEntry struct {
 	Repository string `db:"repository"`
 }
 
+// ArchivedTargetEntry records a target which was removed as part of stale
+// target cleanup. Properties holds the target properties as JSON.
+type ArchivedTargetEntry struct {
+	ProjectID  string    `db:"project"`
+	TargetName string    `db:"target"`
+	Properties string    `db:"properties"`
+	ArchivedAt time.Time `db:"archived_at"`
+}
+
+// ReadOnlyEntry records whether the service is in read-only mode, shared by
+// all service instances. The table holds at most one row.
+type ReadOnlyEntry struct {
+	ReadOnly  bool      `db:"read_only"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// TargetActivityEntry records when a workflow was last submitted for a
+// target. Unlike workflows in Argo, it is kept until the target is archived.
+type TargetActivityEntry struct {
+	ProjectID       string    `db:"project"`
+	TargetName      string    `db:"target"`
+	LastSubmittedAt time.Time `db:"last_submitted_at"`
+}
+
 // Client allows for db crud operations
 type Client interface {
 	CreateProjectEntry(ctx context.Context, pe ProjectEntry) error
 	ReadProjectEntry(ctx context.Context, project string) (ProjectEntry, error)
 	DeleteProjectEntry(ctx context.Context, project string) error
+	ListProjectEntries(ctx context.Context) ([]ProjectEntry, error)
+	CreateArchivedTargetEntry(ctx context.Context, ate ArchivedTargetEntry) error
+	DeleteArchivedTargetEntry(ctx context.Context, ate ArchivedTargetEntry) error
+	ReadReadOnlyEntry(ctx context.Context) (ReadOnlyEntry, error)
+	UpdateReadOnlyEntry(ctx context.Context, roe ReadOnlyEntry) error
+	UpdateTargetActivityEntry(ctx context.Context, tae TargetActivityEntry) error
+	ListTargetActivityEntries(ctx context.Context) ([]TargetActivityEntry, error)
 }
 
 // SQLClient allows for db crud operations using postgres db
@@ -28,6 +61,9 @@ type SQLClient struct {
 }
 
 const ProjectEntryDB = "projects"
+const ArchivedTargetEntryDB = "archived_targets"
+const ReadOnlyEntryDB = "read_only"
+const TargetActivityEntryDB = "target_activity"
 
 func NewSQLClient(host, database, user, password string) (SQLClient, error) {
 	return SQLClient{
@@ -91,3 +127,117 @@ func (d SQLClient) DeleteProjectEntry(ctx context.Context, project string) error
 
 	return sess.WithContext(ctx).Collection(ProjectEntryDB).Find("project", project).Delete()
 }
+
+func (d SQLClient) ListProjectEntries(ctx context.Context) ([]ProjectEntry, error) {
+	res := []ProjectEntry{}
+
+	sess, err := d.createSession()
+	if err != nil {
+		return res, err
+	}
+	defer sess.Close()
+
+	err = sess.WithContext(ctx).Collection(ProjectEntryDB).Find().All(&res)
+	return res, err
+}
+
+func (d SQLClient) CreateArchivedTargetEntry(ctx context.Context, ate ArchivedTargetEntry) error {
+	sess, err := d.createSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	_, err = sess.WithContext(ctx).Collection(ArchivedTargetEntryDB).Insert(ate)
+	return err
+}
+
+// DeleteArchivedTargetEntry deletes the entry matching the project, target
+// and archived at time.
+func (d SQLClient) DeleteArchivedTargetEntry(ctx context.Context, ate ArchivedTargetEntry) error {
+	sess, err := d.createSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	return sess.WithContext(ctx).Collection(ArchivedTargetEntryDB).Find(db.Cond{
+		"project":     ate.ProjectID,
+		"target":      ate.TargetName,
+		"archived_at": ate.ArchivedAt,
+	}).Delete()
+}
+
+// ReadReadOnlyEntry returns the read-only entry, read-only mode is disabled
+// when there is no entry.
+func (d SQLClient) ReadReadOnlyEntry(ctx context.Context) (ReadOnlyEntry, error) {
+	res := ReadOnlyEntry{}
+
+	sess, err := d.createSession()
+	if err != nil {
+		return res, err
+	}
+	defer sess.Close()
+
+	err = sess.WithContext(ctx).Collection(ReadOnlyEntryDB).Find().One(&res)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return ReadOnlyEntry{}, nil
+	}
+	return res, err
+}
+
+func (d SQLClient) UpdateReadOnlyEntry(ctx context.Context, roe ReadOnlyEntry) error {
+	sess, err := d.createSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	return sess.WithContext(ctx).Tx(func(sess db.Session) error {
+		if err := sess.Collection(ReadOnlyEntryDB).Find().Delete(); err != nil {
+			return err
+		}
+
+		if _, err = sess.Collection(ReadOnlyEntryDB).Insert(roe); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (d SQLClient) UpdateTargetActivityEntry(ctx context.Context, tae TargetActivityEntry) error {
+	sess, err := d.createSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	return sess.WithContext(ctx).Tx(func(sess db.Session) error {
+		if err := sess.Collection(TargetActivityEntryDB).Find(db.Cond{
+			"project": tae.ProjectID,
+			"target":  tae.TargetName,
+		}).Delete(); err != nil {
+			return err
+		}
+
+		if _, err = sess.Collection(TargetActivityEntryDB).Insert(tae); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (d SQLClient) ListTargetActivityEntries(ctx context.Context) ([]TargetActivityEntry, error) {
+	res := []TargetActivityEntry{}
+
+	sess, err := d.createSession()
+	if err != nil {
+		return res, err
+	}
+	defer sess.Close()
+
+	err = sess.WithContext(ctx).Collection(TargetActivityEntryDB).Find().All(&res)
+	return res, err
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,6 +25,8 @@ var prefixedEnvVars = map[string]string{
 	"_DB_NAME":                      "argocloudops",
 	"_DB_USER":                      "argoco",
 	"_DB_PASSWORD":                  "1234",
+	"_TIMEOUT_CHECK_INTERVAL":       "30s",
+	"_READ_ONLY":                    "true",
 }
 
 var nonPrefixedEnvVars = map[string]string{
@@ -79,6 +82,8 @@ func TestGetEnv(t *testing.T) {
 	assert.Equal(t, "argocloudops", vars.DBName)
 	assert.Equal(t, "argoco", vars.DBUser)
 	assert.Equal(t, "1234", vars.DBPassword)
+	assert.Equal(t, 30*time.Second, vars.TimeoutCheckInterval)
+	assert.True(t, vars.ReadOnly)
 }
 
 func TestDefaults(t *testing.T) {
@@ -0,0 +1,74 @@
+package iam
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	awsiam "github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+// RoleChecker checks whether IAM roles exist.
+type RoleChecker interface {
+	RoleExists(roleArn string) (bool, error)
+}
+
+// NewAWSRoleChecker creates a RoleChecker backed by the IAM API.
+// The credentials used must be able to call iam:GetRole in their own account.
+func NewAWSRoleChecker(p client.ConfigProvider) RoleChecker {
+	return AWSRoleChecker{
+		svc:    awsiam.New(p),
+		stsSvc: sts.New(p),
+	}
+}
+
+// AWSRoleChecker checks whether IAM roles exist using the IAM API.
+type AWSRoleChecker struct {
+	svc    iamiface.IAMAPI
+	stsSvc stsiface.STSAPI
+}
+
+// RoleExists returns whether the role exists. An error is returned when
+// existence could not be determined, such as when access is denied or the
+// role is in a different account than the credentials used.
+func (c AWSRoleChecker) RoleExists(roleArn string) (bool, error) {
+	parsed, err := arn.Parse(roleArn)
+	if err != nil {
+		return false, fmt.Errorf("invalid role arn: %w", err)
+	}
+
+	// GetRole only looks up roles in the caller's account, so a role in any
+	// other account would always appear not to exist.
+	identity, err := c.stsSvc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return false, fmt.Errorf("sts get caller identity error: %w", err)
+	}
+	if parsed.AccountID != aws.StringValue(identity.Account) {
+		return false, fmt.Errorf("role is in account '%s', unable to check from account '%s'", parsed.AccountID, aws.StringValue(identity.Account))
+	}
+
+	// The resource is 'role/<path/>name', GetRole only takes the name.
+	if !strings.HasPrefix(parsed.Resource, "role/") {
+		return false, errors.New("arn is not a role arn")
+	}
+	name := parsed.Resource[strings.LastIndex(parsed.Resource, "/")+1:]
+
+	_, err = c.svc.GetRole(&awsiam.GetRoleInput{RoleName: aws.String(name)})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == awsiam.ErrCodeNoSuchEntityException {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("iam get role error: %w", err)
+	}
+
+	return true, nil
+}
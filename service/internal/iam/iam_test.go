@@ -0,0 +1,124 @@
+package iam
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsiam "github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+func TestAWSRoleCheckerRoleExists(t *testing.T) {
+	tests := []struct {
+		name      string
+		roleArn   string
+		iamErr    error
+		stsErr    error
+		wantRole  string
+		exists    bool
+		errResult bool
+	}{
+		{
+			name:     "role exists",
+			roleArn:  "arn:aws:iam::012345678901:role/test-role",
+			wantRole: "test-role",
+			exists:   true,
+		},
+		{
+			name:     "role with path exists",
+			roleArn:  "arn:aws:iam::012345678901:role/some/path/test-role",
+			wantRole: "test-role",
+			exists:   true,
+		},
+		{
+			name:     "role does not exist",
+			roleArn:  "arn:aws:iam::012345678901:role/test-role",
+			iamErr:   awserr.New(awsiam.ErrCodeNoSuchEntityException, "not found", nil),
+			wantRole: "test-role",
+		},
+		{
+			name:      "iam error",
+			roleArn:   "arn:aws:iam::012345678901:role/test-role",
+			iamErr:    fmt.Errorf("access denied"),
+			wantRole:  "test-role",
+			errResult: true,
+		},
+		{
+			name:      "role in another account",
+			roleArn:   "arn:aws:iam::999999999999:role/test-role",
+			errResult: true,
+		},
+		{
+			name:      "sts error",
+			roleArn:   "arn:aws:iam::012345678901:role/test-role",
+			stsErr:    fmt.Errorf("access denied"),
+			errResult: true,
+		},
+		{
+			name:      "invalid arn",
+			roleArn:   "test-role",
+			errResult: true,
+		},
+		{
+			name:      "not a role arn",
+			roleArn:   "arn:aws:iam::012345678901:policy/test-policy",
+			errResult: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := AWSRoleChecker{
+				svc:    mockIAM{t: t, err: tt.iamErr, wantRole: tt.wantRole},
+				stsSvc: mockSTS{err: tt.stsErr},
+			}
+
+			exists, err := c.RoleExists(tt.roleArn)
+			if err != nil {
+				if !tt.errResult {
+					t.Errorf("\ndid not expect error, got: %v", err)
+				}
+			} else {
+				if tt.errResult {
+					t.Errorf("\nexpected error")
+				}
+				if exists != tt.exists {
+					t.Errorf("\nwant: %v\n got: %v", tt.exists, exists)
+				}
+			}
+		})
+	}
+}
+
+type mockIAM struct {
+	iamiface.IAMAPI
+	t        *testing.T
+	wantRole string
+	err      error
+}
+
+func (m mockIAM) GetRole(in *awsiam.GetRoleInput) (*awsiam.GetRoleOutput, error) {
+	if *in.RoleName != m.wantRole {
+		m.t.Errorf("\nwant role: %v\n got: %v", m.wantRole, *in.RoleName)
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &awsiam.GetRoleOutput{}, nil
+}
+
+type mockSTS struct {
+	stsiface.STSAPI
+	err error
+}
+
+func (m mockSTS) GetCallerIdentity(in *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &sts.GetCallerIdentityOutput{Account: aws.String("012345678901")}, nil
+}
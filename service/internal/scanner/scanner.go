@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+)
+
+// Scanner scans content for hard-coded secrets.
+type Scanner interface {
+	Scan(content []byte) ([]Finding, error)
+}
+
+// Finding represents a potential secret found in content. The secret itself
+// is never included.
+type Finding struct {
+	RuleID string `json:"rule_id"`
+	Line   int    `json:"line"`
+}
+
+// Rule represents a pattern which matches a type of secret.
+type Rule struct {
+	ID    string
+	Regex *regexp.Regexp
+}
+
+// DefaultRules are a subset of the gitleaks rules for commonly leaked
+// credentials.
+var DefaultRules = []Rule{
+	{
+		ID:    "aws-access-key-id",
+		Regex: regexp.MustCompile(`\b(A3T[A-Z0-9]|AKIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA|ASIA)[A-Z0-9]{16}\b`),
+	},
+	{
+		ID:    "aws-secret-access-key",
+		Regex: regexp.MustCompile(`(?i)aws_?secret_?(access_?)?key["']?\s*[:=]\s*["']?[0-9a-zA-Z/+]{40}\b`),
+	},
+	{
+		ID:    "private-key",
+		Regex: regexp.MustCompile(`-----BEGIN ((EC|PGP|DSA|RSA|OPENSSH) )?PRIVATE KEY( BLOCK)?-----`),
+	},
+	{
+		ID:    "github-token",
+		Regex: regexp.MustCompile(`\b(ghp|gho|ghu|ghs|ghr)_[0-9a-zA-Z]{36}\b`),
+	},
+	{
+		ID:    "slack-token",
+		Regex: regexp.MustCompile(`\bxox[baprs]-[0-9a-zA-Z-]{10,48}\b`),
+	},
+}
+
+// NewRegexScanner creates a scanner which matches content line by line
+// against the given rules.
+func NewRegexScanner(rules ...Rule) Scanner {
+	return RegexScanner{
+		rules: rules,
+	}
+}
+
+// RegexScanner scans content using regular expression rules.
+type RegexScanner struct {
+	rules []Rule
+}
+
+// Scan returns a finding for each rule matched on each line of content.
+func (s RegexScanner) Scan(content []byte) ([]Finding, error) {
+	findings := []Finding{}
+
+	lines := bufio.NewScanner(bytes.NewReader(content))
+	lines.Buffer(make([]byte, bufio.MaxScanTokenSize), len(content)+1)
+	for line := 1; lines.Scan(); line++ {
+		for _, rule := range s.rules {
+			if rule.Regex.Match(lines.Bytes()) {
+				findings = append(findings, Finding{RuleID: rule.ID, Line: line})
+			}
+		}
+	}
+
+	return findings, lines.Err()
+}
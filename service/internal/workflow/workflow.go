@@ -6,23 +6,45 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/cello-proj/cello/service/internal/scanner"
 
 	argoWorkflowAPIClient "github.com/argoproj/argo-workflows/v3/pkg/apiclient/workflow"
 	argoWorkflowAPISpec "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
 const mainContainer = "main"
 
+const (
+	// DeadlineLabel is the workflow label holding the unix time after which
+	// the operation is considered timed out.
+	DeadlineLabel = "cello-deadline"
+	// TokenAccessorLabel is the workflow label holding the accessor of the
+	// credentials token issued to the workflow.
+	TokenAccessorLabel = "cello-token-accessor"
+	// SecretFindingsAnnotation is the workflow annotation holding the
+	// potential secrets found when scanning the operation manifest.
+	SecretFindingsAnnotation = "cello-secret-findings"
+	// StatusFailedTimeout is the status of a workflow which was stopped
+	// because it ran past its deadline, see Stop.
+	StatusFailedTimeout = "failed-timeout"
+)
+
 // Workflow interface is used for interacting with workflow services.
 type Workflow interface {
 	List(ctx context.Context) ([]string, error)
+	ListStatus(ctx context.Context, labelSelector string) ([]Status, error)
 	Logs(ctx context.Context, workflowName string) (*Logs, error)
 	LogStream(ctx context.Context, workflowName string, data http.ResponseWriter) error
 	Status(ctx context.Context, workflowName string) (*Status, error)
-	Submit(ctx context.Context, from string, parameters map[string]string, labels map[string]string) (string, error)
+	Stop(ctx context.Context, workflowName string) error
+	Submit(ctx context.Context, from string, parameters map[string]string, labels map[string]string, annotations map[string]string) (string, error)
 }
 
 // NewArgoWorkflow creates an Argo workflow.
@@ -63,12 +85,39 @@ func (a ArgoWorkflow) List(ctx context.Context) ([]string, error) {
 	return workflowIDs, nil
 }
 
+// ListStatus returns the status of each workflow matching the label
+// selector, all workflows are returned when the selector is empty.
+func (a ArgoWorkflow) ListStatus(ctx context.Context, labelSelector string) ([]Status, error) {
+	statuses := []Status{}
+
+	workflowListResult, err := a.svc.ListWorkflows(ctx, &argoWorkflowAPIClient.WorkflowListRequest{
+		Namespace: a.namespace,
+		ListOptions: &metav1.ListOptions{
+			LabelSelector: labelSelector,
+		},
+	})
+
+	if err != nil {
+		return statuses, err
+	}
+
+	for i := range workflowListResult.Items {
+		statuses = append(statuses, newStatus(&workflowListResult.Items[i]))
+	}
+
+	return statuses, nil
+}
+
 // Status represents a workflow status.
 type Status struct {
 	Name     string `json:"name"`
 	Status   string `json:"status"`
 	Created  string `json:"created"`
 	Finished string `json:"finished"`
+	// Potential secrets found when scanning the operation manifest.
+	SecretFindings []scanner.Finding `json:"secret_findings,omitempty"`
+	// Labels are not returned to clients.
+	Labels map[string]string `json:"-"`
 }
 
 // Status returns a workflow status.
@@ -82,14 +131,28 @@ func (a ArgoWorkflow) Status(ctx context.Context, workflowName string) (*Status,
 		return nil, err
 	}
 
-	workflowData := Status{
-		Name:     workflowName,
-		Status:   strings.ToLower(string(workflow.Status.Phase)),
-		Created:  fmt.Sprint(workflow.CreationTimestamp.Unix()),
-		Finished: fmt.Sprint(workflow.Status.FinishedAt.Unix()),
+	workflowData := newStatus(workflow)
+	return &workflowData, nil
+}
+
+func newStatus(workflow *argoWorkflowAPISpec.Workflow) Status {
+	// Workflows stopped after their deadline timed out. Workflows which fail
+	// on their own after their deadline have no shutdown strategy.
+	status := strings.ToLower(string(workflow.Status.Phase))
+	if deadline, ok := Deadline(workflow.Labels); ok && status == "failed" &&
+		workflow.Spec.Shutdown == argoWorkflowAPISpec.ShutdownStrategyStop &&
+		!workflow.Status.FinishedAt.Time.Before(deadline) {
+		status = StatusFailedTimeout
 	}
 
-	return &workflowData, nil
+	return Status{
+		Name:           workflow.Name,
+		Status:         status,
+		Created:        fmt.Sprint(workflow.CreationTimestamp.Unix()),
+		Finished:       fmt.Sprint(workflow.Status.FinishedAt.Unix()),
+		SecretFindings: parseSecretFindings(workflow.Annotations[SecretFindingsAnnotation]),
+		Labels:         workflow.Labels,
+	}
 }
 
 // Logs returns logs for a workflow.
@@ -166,8 +229,70 @@ func (a ArgoWorkflow) LogStream(ctx context.Context, workflowName string, w http
 	}
 }
 
+// Stop stops a running workflow. Argo records the stop as the workflow's
+// shutdown strategy, which Status uses to report timed out workflows.
+func (a ArgoWorkflow) Stop(ctx context.Context, workflowName string) error {
+	_, err := a.svc.StopWorkflow(ctx, &argoWorkflowAPIClient.WorkflowStopRequest{
+		Name:      workflowName,
+		Namespace: a.namespace,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to stop workflow: %w", err)
+	}
+
+	return nil
+}
+
+// Deadline returns the deadline recorded in the workflow labels and whether
+// one was set.
+func Deadline(workflowLabels map[string]string) (time.Time, bool) {
+	v, ok := workflowLabels[DeadlineLabel]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(sec, 0), true
+}
+
+// FormatSecretFindings formats findings as a value for the
+// SecretFindingsAnnotation. Argo does not allow ',' or '=' in annotation
+// values, so findings are formatted as 'rule_id:line' separated by ';'.
+func FormatSecretFindings(findings []scanner.Finding) string {
+	formatted := make([]string, 0, len(findings))
+	for _, f := range findings {
+		formatted = append(formatted, fmt.Sprintf("%s:%d", f.RuleID, f.Line))
+	}
+	return strings.Join(formatted, ";")
+}
+
+// Parses findings formatted by FormatSecretFindings, skipping any which are
+// malformed.
+func parseSecretFindings(v string) []scanner.Finding {
+	var findings []scanner.Finding
+	for _, formatted := range strings.Split(v, ";") {
+		parts := strings.SplitN(formatted, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		line, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+
+		findings = append(findings, scanner.Finding{RuleID: parts[0], Line: line})
+	}
+	return findings
+}
+
 // Submit submits a workflow execution.
-func (a ArgoWorkflow) Submit(ctx context.Context, from string, parameters map[string]string, workflowLabels map[string]string) (string, error) {
+func (a ArgoWorkflow) Submit(ctx context.Context, from string, parameters map[string]string, workflowLabels map[string]string, workflowAnnotations map[string]string) (string, error) {
 	parts := strings.SplitN(from, "/", 2)
 	for _, part := range parts {
 		if part == "" {
@@ -193,6 +318,9 @@ func (a ArgoWorkflow) Submit(ctx context.Context, from string, parameters map[st
 			GenerateName: generateNamePrefix,
 			Parameters:   parameterStrings,
 			Labels:       labels.FormatLabels(workflowLabels),
+			// Unlike labels.FormatLabels, an empty string is returned when there
+			// are no annotations.
+			Annotations: labels.Set(workflowAnnotations).String(),
 		},
 	})
 
@@ -234,5 +362,6 @@ func NewParameters(environmentVariablesString, executeCommand, executeContainerI
 
 // CreateWorkflowResponse creates a workflow response.
 type CreateWorkflowResponse struct {
-	WorkflowName string `json:"workflow_name"`
+	WorkflowName   string            `json:"workflow_name"`
+	SecretFindings []scanner.Finding `json:"secret_findings,omitempty"`
 }
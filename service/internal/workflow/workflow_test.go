@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/cello-proj/cello/service/internal/scanner"
 
 	argoWorkflowAPIClient "github.com/argoproj/argo-workflows/v3/pkg/apiclient/workflow"
 	"github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
@@ -62,10 +65,77 @@ func TestArgoWorkflowsList(t *testing.T) {
 	}
 }
 
+func TestArgoListStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		selector  string
+		listErr   error
+		output    []Status
+		errResult error
+	}{
+		{
+			name:     "list workflow status success",
+			selector: DeadlineLabel,
+			output: []Status{
+				{
+					Name:     "testWorkflow1",
+					Status:   "running",
+					Created:  fmt.Sprint(time.Time{}.Unix()),
+					Finished: fmt.Sprint(time.Time{}.Unix()),
+					Labels:   map[string]string{DeadlineLabel: "1640995200"},
+				},
+			},
+		},
+		{
+			name:      "list workflow status error",
+			listErr:   fmt.Errorf("list error"),
+			output:    []Status{},
+			errResult: fmt.Errorf("list error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var selector string
+			argoWf := NewArgoWorkflow(
+				mockArgoClient{
+					status:   v1alpha1.WorkflowRunning,
+					labels:   map[string]string{DeadlineLabel: "1640995200"},
+					selector: &selector,
+					err:      tt.listErr,
+				},
+				"namespace",
+			)
+
+			out, err := argoWf.ListStatus(context.Background(), tt.selector)
+			if err != nil {
+				if tt.errResult == nil || tt.errResult.Error() != err.Error() {
+					t.Errorf("\nwant: %v\n got: %v", tt.errResult, err)
+				}
+			} else if tt.errResult != nil {
+				t.Errorf("\nwant: %v\n got: %v", tt.errResult, err)
+			}
+
+			if !cmp.Equal(out, tt.output) {
+				t.Errorf("\nwant: %v\n got: %v", tt.output, out)
+			}
+
+			if err == nil && selector != tt.selector {
+				t.Errorf("\nwant: %v\n got: %v", tt.selector, selector)
+			}
+		})
+	}
+}
+
 func TestArgoStatus(t *testing.T) {
+	deadline := time.Unix(1640995200, 0)
+
 	tests := []struct {
 		name               string
 		argoWorkflowStatus v1alpha1.WorkflowPhase
+		labels             map[string]string
+		shutdown           v1alpha1.ShutdownStrategy
+		finished           time.Time
 		statusErr          error
 		result             string
 		errResult          error
@@ -85,6 +155,28 @@ func TestArgoStatus(t *testing.T) {
 			argoWorkflowStatus: v1alpha1.WorkflowFailed,
 			result:             "failed",
 		},
+		{
+			name:               "get workflow status failed before deadline",
+			argoWorkflowStatus: v1alpha1.WorkflowFailed,
+			labels:             map[string]string{DeadlineLabel: "1640995200"},
+			finished:           deadline.Add(-time.Minute),
+			result:             "failed",
+		},
+		{
+			name:               "get workflow status failed after deadline without stop",
+			argoWorkflowStatus: v1alpha1.WorkflowFailed,
+			labels:             map[string]string{DeadlineLabel: "1640995200"},
+			finished:           deadline.Add(time.Minute),
+			result:             "failed",
+		},
+		{
+			name:               "get workflow status failed timeout",
+			argoWorkflowStatus: v1alpha1.WorkflowFailed,
+			labels:             map[string]string{DeadlineLabel: "1640995200"},
+			shutdown:           v1alpha1.ShutdownStrategyStop,
+			finished:           deadline.Add(time.Minute),
+			result:             "failed-timeout",
+		},
 		{
 			name:               "get workflow status succeeded",
 			argoWorkflowStatus: v1alpha1.WorkflowSucceeded,
@@ -100,7 +192,7 @@ func TestArgoStatus(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			argoWf := NewArgoWorkflow(
-				mockArgoClient{status: tt.argoWorkflowStatus, err: tt.statusErr},
+				mockArgoClient{status: tt.argoWorkflowStatus, labels: tt.labels, shutdown: tt.shutdown, finished: tt.finished, err: tt.statusErr},
 				"namespace",
 			)
 
@@ -147,7 +239,7 @@ func TestArgoSubmit(t *testing.T) {
 				"namespace",
 			)
 
-			workflow, err := argoWf.Submit(context.Background(), "test/test", map[string]string{"param": "value"}, map[string]string{"X-B3-TraceId": "test-txid"})
+			workflow, err := argoWf.Submit(context.Background(), "test/test", map[string]string{"param": "value"}, map[string]string{"X-B3-TraceId": "test-txid"}, map[string]string{SecretFindingsAnnotation: "aws-access-key-id:2"})
 			if err != nil {
 				if tt.errResult != nil && tt.errResult.Error() != err.Error() {
 					t.Errorf("\nwant: %v\n got: %v", tt.errResult, err)
@@ -164,25 +256,149 @@ func TestArgoSubmit(t *testing.T) {
 	}
 }
 
+func TestArgoStop(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		errResult error
+	}{
+		{
+			name: "stop workflow",
+		},
+		{
+			name:      "stop workflow error",
+			err:       fmt.Errorf("stop error"),
+			errResult: fmt.Errorf("failed to stop workflow: stop error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argoWf := NewArgoWorkflow(
+				mockArgoClient{err: tt.err},
+				"namespace",
+			)
+
+			err := argoWf.Stop(context.Background(), "workflow")
+			if err != nil {
+				if tt.errResult == nil || tt.errResult.Error() != err.Error() {
+					t.Errorf("\nwant: %v\n got: %v", tt.errResult, err)
+				}
+			} else if tt.errResult != nil {
+				t.Errorf("\nwant: %v\n got: %v", tt.errResult, err)
+			}
+		})
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		deadline time.Time
+		ok       bool
+	}{
+		{
+			name:     "deadline set",
+			labels:   map[string]string{DeadlineLabel: "1640995200"},
+			deadline: time.Unix(1640995200, 0),
+			ok:       true,
+		},
+		{
+			name:   "deadline not set",
+			labels: map[string]string{},
+		},
+		{
+			name:   "deadline invalid",
+			labels: map[string]string{DeadlineLabel: "soon"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadline, ok := Deadline(tt.labels)
+			if ok != tt.ok {
+				t.Errorf("\nwant: %v\n got: %v", tt.ok, ok)
+			}
+			if !deadline.Equal(tt.deadline) {
+				t.Errorf("\nwant: %v\n got: %v", tt.deadline, deadline)
+			}
+		})
+	}
+}
+
+func TestSecretFindings(t *testing.T) {
+	tests := []struct {
+		name      string
+		findings  []scanner.Finding
+		formatted string
+	}{
+		{
+			name: "no findings",
+		},
+		{
+			name:      "single finding",
+			findings:  []scanner.Finding{{RuleID: "aws-access-key-id", Line: 2}},
+			formatted: "aws-access-key-id:2",
+		},
+		{
+			name: "multiple findings",
+			findings: []scanner.Finding{
+				{RuleID: "aws-access-key-id", Line: 2},
+				{RuleID: "private-key", Line: 10},
+			},
+			formatted: "aws-access-key-id:2;private-key:10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatted := FormatSecretFindings(tt.findings)
+			if formatted != tt.formatted {
+				t.Errorf("\nwant: %v\n got: %v", tt.formatted, formatted)
+			}
+
+			findings := parseSecretFindings(formatted)
+			if !cmp.Equal(findings, tt.findings) {
+				t.Errorf("\nwant: %v\n got: %v", tt.findings, findings)
+			}
+		})
+	}
+}
+
 type mockArgoClient struct {
 	argoWorkflowAPIClient.WorkflowServiceClient
-	status v1alpha1.WorkflowPhase
-	err    error
+	status   v1alpha1.WorkflowPhase
+	labels   map[string]string
+	shutdown v1alpha1.ShutdownStrategy
+	finished time.Time
+	selector *string
+	err      error
 }
 
 func (m mockArgoClient) ListWorkflows(ctx context.Context, in *argoWorkflowAPIClient.WorkflowListRequest, opts ...grpc.CallOption) (*v1alpha1.WorkflowList, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
+	if m.selector != nil && in.ListOptions != nil {
+		*m.selector = in.ListOptions.LabelSelector
+	}
 	return &v1alpha1.WorkflowList{Items: []v1alpha1.Workflow{
-		{TypeMeta: v1.TypeMeta{}, ObjectMeta: v1.ObjectMeta{Name: "testWorkflow1"}}}}, nil
+		{TypeMeta: v1.TypeMeta{}, ObjectMeta: v1.ObjectMeta{Name: "testWorkflow1", Labels: m.labels}, Status: v1alpha1.WorkflowStatus{Phase: m.status}}}}, nil
 }
 
 func (m mockArgoClient) GetWorkflow(ctx context.Context, in *argoWorkflowAPIClient.WorkflowGetRequest, opts ...grpc.CallOption) (*v1alpha1.Workflow, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
-	return &v1alpha1.Workflow{TypeMeta: v1.TypeMeta{}, ObjectMeta: v1.ObjectMeta{Name: "testWorkflow1"}, Status: v1alpha1.WorkflowStatus{Phase: m.status}}, nil
+	return &v1alpha1.Workflow{TypeMeta: v1.TypeMeta{}, ObjectMeta: v1.ObjectMeta{Name: "testWorkflow1", Labels: m.labels}, Spec: v1alpha1.WorkflowSpec{Shutdown: m.shutdown}, Status: v1alpha1.WorkflowStatus{Phase: m.status, FinishedAt: v1.NewTime(m.finished)}}, nil
+}
+
+func (m mockArgoClient) StopWorkflow(ctx context.Context, in *argoWorkflowAPIClient.WorkflowStopRequest, opts ...grpc.CallOption) (*v1alpha1.Workflow, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &v1alpha1.Workflow{TypeMeta: v1.TypeMeta{}, ObjectMeta: v1.ObjectMeta{Name: "testWorkflow1"}, Status: v1alpha1.WorkflowStatus{Phase: v1alpha1.WorkflowFailed}}, nil
 }
 
 func (m mockArgoClient) SubmitWorkflow(ctx context.Context, in *argoWorkflowAPIClient.WorkflowSubmitRequest, opts ...grpc.CallOption) (*v1alpha1.Workflow, error) {
@@ -13,9 +13,12 @@ import (
 	"github.com/cello-proj/cello/service/internal/db"
 	"github.com/cello-proj/cello/service/internal/env"
 	"github.com/cello-proj/cello/service/internal/git"
+	"github.com/cello-proj/cello/service/internal/iam"
+	"github.com/cello-proj/cello/service/internal/scanner"
 	"github.com/cello-proj/cello/service/internal/workflow"
 
 	"github.com/argoproj/argo-workflows/v3/cmd/argo/commands/client"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 )
@@ -71,9 +74,17 @@ func main() {
 		gitClient:              gitClient(env, logger),
 		env:                    env,
 		dbClient:               dbClient,
+		readOnly:               newReadOnlyMode(env.ReadOnly, dbClient, logger),
+		secretScanner:          scanner.NewRegexScanner(scanner.DefaultRules...),
+		roleChecker:            iam.NewAWSRoleChecker(session.Must(session.NewSession())),
 	}
 
-	level.Info(logger).Log("message", "starting web service", "vault addr", env.VaultAddress, "argoAddr", env.ArgoAddress)
+	if config.Timeouts.enabled() {
+		level.Info(logger).Log("message", "starting operation timeout watcher", "interval", env.TimeoutCheckInterval)
+		go h.watchTimeouts(env.TimeoutCheckInterval)
+	}
+
+	level.Info(logger).Log("message", "starting web service", "vault addr", env.VaultAddress, "argoAddr", env.ArgoAddress, "read-only", env.ReadOnly)
 	if err := http.ListenAndServeTLS(fmt.Sprintf(":%d", env.Port), "ssl/certificate.crt", "ssl/certificate.key", setupRouter(h)); err != nil {
 		level.Error(logger).Log("message", "error starting service", "error", err)
 		panic("error starting service")
@@ -9,12 +9,16 @@ import (
 
 const (
 	txIDHeader = "X-B3-TraceId"
+
+	readOnlyPath    = "/admin/read-only"
+	readOnlyMessage = "service is in read-only mode for maintenance, please try again later"
 )
 
 func setupRouter(h handler) *mux.Router {
 	r := mux.NewRouter()
 	r.Use(commonMiddleware)
 	r.Use(txIDMiddleware)
+	r.Use(h.readOnlyMiddleware)
 
 	r.HandleFunc("/workflows", h.createWorkflow).Methods(http.MethodPost)
 	r.HandleFunc("/workflows/{workflowName}", h.getWorkflow).Methods(http.MethodGet)
@@ -31,6 +35,10 @@ func setupRouter(h handler) *mux.Router {
 	r.HandleFunc("/projects/{projectName}/targets/{targetName}/operations", h.createWorkflowFromGit).Methods(http.MethodPost)
 	r.HandleFunc("/projects/{projectName}/targets/{targetName}/workflows", h.listWorkflows).Methods(http.MethodGet)
 	r.HandleFunc("/health/full", h.healthCheck).Methods(http.MethodGet)
+	r.HandleFunc(readOnlyPath, h.getReadOnly).Methods(http.MethodGet)
+	r.HandleFunc(readOnlyPath, h.setReadOnly).Methods(http.MethodPut)
+	r.HandleFunc("/admin/stale-targets", h.getStaleTargets).Methods(http.MethodGet)
+	r.HandleFunc("/admin/stale-targets/archive", h.archiveStaleTargets).Methods(http.MethodPost)
 	return r
 }
 
@@ -49,3 +57,15 @@ func txIDMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Rejects all requests other than reads and changes to the read-only mode
+// itself while the service is in read-only mode.
+func (h handler) readOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.readOnly.isEnabled() && r.Method != http.MethodGet && r.Method != http.MethodHead && r.URL.Path != readOnlyPath {
+			h.errorResponse(w, readOnlyMessage, http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
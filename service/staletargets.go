@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cello-proj/cello/internal/requests"
+	"github.com/cello-proj/cello/internal/responses"
+	"github.com/cello-proj/cello/service/internal/credentials"
+	"github.com/cello-proj/cello/service/internal/db"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// Reports targets which are candidates for cleanup
+func (h handler) getStaleTargets(w http.ResponseWriter, r *http.Request) {
+	l := h.requestLogger(r, "op", "get-stale-targets")
+
+	level.Debug(l).Log("message", "validating authorization header for get stale targets")
+	ah := r.Header.Get("Authorization")
+	a, err := credentials.NewAuthorization(ah)
+	if err != nil {
+		h.errorResponse(w, "error unauthorized, invalid authorization header format", http.StatusUnauthorized)
+		return
+	}
+	if err := a.Validate(a.ValidateAuthorizedAdmin(h.env.AdminSecret)); err != nil {
+		h.errorResponse(w, "error unauthorized, invalid authorization header", http.StatusUnauthorized)
+		return
+	}
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 1 {
+		level.Error(l).Log("message", "error invalid request", "error", "days must be a positive integer")
+		h.errorResponse(w, "invalid request, days must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	level.Debug(l).Log("message", "creating credential provider")
+	cp, err := h.newCredentialsProvider(*a, h.env, r.Header, credentials.NewVaultConfig, credentials.NewVaultSvc)
+	if err != nil {
+		level.Error(l).Log("message", "error creating credentials provider", "error", err)
+		h.errorResponse(w, "error creating credentials provider", http.StatusInternalServerError)
+		return
+	}
+
+	level.Debug(l).Log("message", "finding stale targets", "days", days)
+	report, err := h.findStaleTargets(r.Context(), cp, days, time.Now(), l)
+	if err != nil {
+		level.Error(l).Log("message", "error finding stale targets", "error", err)
+		h.errorResponse(w, "error finding stale targets", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		level.Error(l).Log("message", "error creating response", "error", err)
+		h.errorResponse(w, "error creating response object", http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprint(w, string(data))
+}
+
+// Archives approved targets which are still candidates for cleanup
+func (h handler) archiveStaleTargets(w http.ResponseWriter, r *http.Request) {
+	l := h.requestLogger(r, "op", "archive-stale-targets")
+
+	level.Debug(l).Log("message", "validating authorization header for archive stale targets")
+	ah := r.Header.Get("Authorization")
+	a, err := credentials.NewAuthorization(ah)
+	if err != nil {
+		h.errorResponse(w, "error unauthorized, invalid authorization header format", http.StatusUnauthorized)
+		return
+	}
+	if err := a.Validate(a.ValidateAuthorizedAdmin(h.env.AdminSecret)); err != nil {
+		h.errorResponse(w, "error unauthorized, invalid authorization header", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+
+	var astr requests.ArchiveStaleTargets
+	reqBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		level.Error(l).Log("message", "error reading request body", "error", err)
+		h.errorResponse(w, "error reading request body", http.StatusInternalServerError)
+		return
+	}
+	if err := json.Unmarshal(reqBody, &astr); err != nil {
+		level.Error(l).Log("message", "error decoding request", "error", err)
+		h.errorResponse(w, "error decoding request", http.StatusBadRequest)
+		return
+	}
+	if err := astr.Validate(); err != nil {
+		level.Error(l).Log("message", "error invalid request", "error", err)
+		h.errorResponse(w, fmt.Sprintf("invalid request, %s", err), http.StatusBadRequest)
+		return
+	}
+
+	level.Debug(l).Log("message", "creating credential provider")
+	cp, err := h.newCredentialsProvider(*a, h.env, r.Header, credentials.NewVaultConfig, credentials.NewVaultSvc)
+	if err != nil {
+		level.Error(l).Log("message", "error creating credentials provider", "error", err)
+		h.errorResponse(w, "error creating credentials provider", http.StatusInternalServerError)
+		return
+	}
+
+	// Targets may have been used or had their role recreated since the
+	// report was approved, so only archive targets which are still stale.
+	level.Debug(l).Log("message", "finding stale targets", "days", astr.Days)
+	report, err := h.findStaleTargets(ctx, cp, astr.Days, time.Now(), l)
+	if err != nil {
+		level.Error(l).Log("message", "error finding stale targets", "error", err)
+		h.errorResponse(w, "error finding stale targets", http.StatusInternalServerError)
+		return
+	}
+
+	candidates := map[string]responses.StaleTarget{}
+	for _, c := range report.Candidates {
+		candidates[fmt.Sprintf("%s/%s", c.ProjectName, c.TargetName)] = c
+	}
+
+	resp := responses.ArchiveStaleTargets{
+		Archived: []responses.StaleTarget{},
+		Skipped:  []responses.SkippedTarget{},
+	}
+	for _, t := range astr.Targets {
+		tl := log.With(l, "project", t.ProjectName, "target", t.TargetName)
+
+		candidate, ok := candidates[fmt.Sprintf("%s/%s", t.ProjectName, t.TargetName)]
+		if !ok {
+			level.Info(tl).Log("message", "skipping target which is not stale")
+			resp.Skipped = append(resp.Skipped, responses.SkippedTarget{
+				ProjectName: t.ProjectName,
+				TargetName:  t.TargetName,
+				Reason:      "target is not stale",
+			})
+			continue
+		}
+
+		if reason, err := h.archiveTarget(ctx, cp, t.ProjectName, t.TargetName, tl); err != nil {
+			level.Error(tl).Log("message", "error archiving target", "error", err)
+			resp.Skipped = append(resp.Skipped, responses.SkippedTarget{
+				ProjectName: t.ProjectName,
+				TargetName:  t.TargetName,
+				Reason:      reason,
+			})
+			continue
+		}
+
+		resp.Archived = append(resp.Archived, candidate)
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		level.Error(l).Log("message", "error creating response", "error", err)
+		h.errorResponse(w, "error creating response object", http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprint(w, string(data))
+}
+
+// Records the target in the db and then deletes it. The record is removed
+// if the target can't be deleted. Returns the reason the target was not
+// archived on error.
+func (h handler) archiveTarget(ctx context.Context, cp credentials.Provider, projectName, targetName string, l log.Logger) (string, error) {
+	level.Debug(l).Log("message", "getting target information")
+	target, err := cp.GetTarget(projectName, targetName)
+	if err != nil {
+		return "error retrieving target", err
+	}
+
+	properties, err := json.Marshal(target.Properties)
+	if err != nil {
+		return "error serializing target properties", err
+	}
+
+	// Postgres timestamps have microsecond precision, truncate so the entry
+	// can be matched when removing it.
+	entry := db.ArchivedTargetEntry{
+		ProjectID:  projectName,
+		TargetName: targetName,
+		Properties: string(properties),
+		ArchivedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	level.Debug(l).Log("message", "inserting archived target into db")
+	if err := h.dbClient.CreateArchivedTargetEntry(ctx, entry); err != nil {
+		return "error archiving target", err
+	}
+
+	level.Info(l).Log("message", "deleting archived target")
+	if err := cp.DeleteTarget(projectName, targetName); err != nil {
+		if dbErr := h.dbClient.DeleteArchivedTargetEntry(ctx, entry); dbErr != nil {
+			level.Error(l).Log("message", "error removing archived target from db", "error", dbErr)
+		}
+		return "error deleting target", err
+	}
+
+	return "", nil
+}
+
+// Finds targets with no workflow activity within the given number of days
+// whose IAM role no longer exists. Activity is the last recorded submission
+// for the target or the workflows still retained by Argo. Targets whose role
+// can't be checked are skipped.
+func (h handler) findStaleTargets(ctx context.Context, cp credentials.Provider, days int, now time.Time, l log.Logger) (responses.StaleTargets, error) {
+	report := responses.StaleTargets{
+		Candidates: []responses.StaleTarget{},
+		Skipped:    []responses.SkippedTarget{},
+	}
+
+	statuses, err := h.argo.ListStatus(h.argoCtx, "")
+	if err != nil {
+		return report, fmt.Errorf("error listing workflows: %w", err)
+	}
+
+	created := map[string]int64{}
+	for _, status := range statuses {
+		c, err := strconv.ParseInt(status.Created, 10, 64)
+		if err != nil {
+			level.Warn(l).Log("message", "unable to parse workflow created time", "workflow", status.Name, "error", err)
+			continue
+		}
+		created[status.Name] = c
+	}
+
+	activities, err := h.dbClient.ListTargetActivityEntries(ctx)
+	if err != nil {
+		return report, fmt.Errorf("error listing target activity: %w", err)
+	}
+
+	submitted := map[string]int64{}
+	for _, a := range activities {
+		submitted[fmt.Sprintf("%s/%s", a.ProjectID, a.TargetName)] = a.LastSubmittedAt.Unix()
+	}
+
+	projects, err := h.dbClient.ListProjectEntries(ctx)
+	if err != nil {
+		return report, fmt.Errorf("error listing projects: %w", err)
+	}
+
+	cutoff := now.AddDate(0, 0, -days).Unix()
+	for _, p := range projects {
+		targets, err := cp.ListTargets(p.ProjectID)
+		if err != nil {
+			return report, fmt.Errorf("error listing targets: %w", err)
+		}
+
+		for _, targetName := range targets {
+			tl := log.With(l, "project", p.ProjectID, "target", targetName)
+
+			lastActivity := submitted[fmt.Sprintf("%s/%s", p.ProjectID, targetName)]
+
+			// Workflows submitted before activity was recorded. Workflow names
+			// are generated from the project and target names and lowercased
+			// by Argo, see workflow.Submit.
+			prefix := strings.ToLower(fmt.Sprintf("%s-%s-", p.ProjectID, targetName))
+			for workflowID, c := range created {
+				if strings.HasPrefix(workflowID, prefix) && c > lastActivity {
+					lastActivity = c
+				}
+			}
+
+			if lastActivity >= cutoff {
+				continue
+			}
+
+			target, err := cp.GetTarget(p.ProjectID, targetName)
+			if err != nil {
+				return report, fmt.Errorf("error retrieving target: %w", err)
+			}
+
+			// Only assumed role targets have a role which can be removed.
+			if target.Properties.RoleArn == "" {
+				continue
+			}
+
+			exists, err := h.roleChecker.RoleExists(target.Properties.RoleArn)
+			if err != nil {
+				level.Warn(tl).Log("message", "unable to check target role", "error", err)
+				report.Skipped = append(report.Skipped, responses.SkippedTarget{
+					ProjectName: p.ProjectID,
+					TargetName:  targetName,
+					Reason:      "unable to check role",
+				})
+				continue
+			}
+
+			if exists {
+				continue
+			}
+
+			candidate := responses.StaleTarget{
+				ProjectName: p.ProjectID,
+				TargetName:  targetName,
+				RoleArn:     target.Properties.RoleArn,
+			}
+			if lastActivity > 0 {
+				candidate.LastActivity = fmt.Sprint(lastActivity)
+			}
+			report.Candidates = append(report.Candidates, candidate)
+		}
+	}
+
+	return report, nil
+}
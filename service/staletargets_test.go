@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cello-proj/cello/internal/responses"
+	"github.com/cello-proj/cello/internal/types"
+	"github.com/cello-proj/cello/service/internal/credentials"
+	"github.com/cello-proj/cello/service/internal/db"
+	"github.com/cello-proj/cello/service/internal/env"
+	"github.com/cello-proj/cello/service/internal/workflow"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStaleDB struct {
+	mockDB
+	archived *[]db.ArchivedTargetEntry
+	activity []db.TargetActivityEntry
+}
+
+func (d mockStaleDB) ListProjectEntries(ctx context.Context) ([]db.ProjectEntry, error) {
+	return []db.ProjectEntry{{ProjectID: "project1"}}, nil
+}
+
+func (d mockStaleDB) ListTargetActivityEntries(ctx context.Context) ([]db.TargetActivityEntry, error) {
+	return d.activity, nil
+}
+
+func (d mockStaleDB) CreateArchivedTargetEntry(ctx context.Context, ate db.ArchivedTargetEntry) error {
+	*d.archived = append(*d.archived, ate)
+	return nil
+}
+
+func (d mockStaleDB) DeleteArchivedTargetEntry(ctx context.Context, ate db.ArchivedTargetEntry) error {
+	for i, a := range *d.archived {
+		if a == ate {
+			*d.archived = append((*d.archived)[:i], (*d.archived)[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type mockStaleCredentialsProvider struct {
+	mockCredentialsProvider
+	deleted *[]string
+}
+
+func (m mockStaleCredentialsProvider) ListTargets(name string) ([]string, error) {
+	return []string{"active", "deleted", "recorded", "MixedCase", "oldexists", "olddeleted", "undeletable", "unchecked"}, nil
+}
+
+func (m mockStaleCredentialsProvider) GetTarget(project string, target string) (types.Target, error) {
+	return types.Target{
+		Name: target,
+		Type: "aws_account",
+		Properties: types.TargetProperties{
+			CredentialType: "assumed_role",
+			RoleArn:        fmt.Sprintf("arn:aws:iam::012345678901:role/%s", target),
+		},
+	}, nil
+}
+
+func (m mockStaleCredentialsProvider) DeleteTarget(project string, target string) error {
+	if target == "undeletable" {
+		return fmt.Errorf("delete error")
+	}
+	*m.deleted = append(*m.deleted, target)
+	return nil
+}
+
+type mockStaleRoleChecker struct{}
+
+func (m mockStaleRoleChecker) RoleExists(roleArn string) (bool, error) {
+	switch roleArn {
+	case "arn:aws:iam::012345678901:role/unchecked":
+		return false, fmt.Errorf("access denied")
+	case "arn:aws:iam::012345678901:role/active",
+		"arn:aws:iam::012345678901:role/oldexists":
+		return true, nil
+	}
+	return false, nil
+}
+
+func newStaleTargetsHandler(now time.Time, archived *[]db.ArchivedTargetEntry, deleted *[]string) handler {
+	recent := fmt.Sprint(now.AddDate(0, 0, -1).Unix())
+	old := fmt.Sprint(now.AddDate(0, 0, -100).Unix())
+
+	return handler{
+		logger: log.NewNopLogger(),
+		newCredentialsProvider: func(a credentials.Authorization, env env.Vars, h http.Header, f credentials.VaultConfigFn, fn credentials.VaultSvcFn) (credentials.Provider, error) {
+			return mockStaleCredentialsProvider{deleted: deleted}, nil
+		},
+		argo: mockStatusWorkflowSvc{
+			statuses: map[string]*workflow.Status{
+				"project1-active-abcde":     {Created: recent},
+				"project1-deleted-abcde":    {Created: recent},
+				"project1-oldexists-abcde":  {Created: old},
+				"project1-olddeleted-abcde": {Created: old},
+				"project1-olddeleted-fghij": {Created: old},
+				"project1-mixedcase-abcde":  {Created: recent},
+			},
+		},
+		argoCtx: context.Background(),
+		env: env.Vars{
+			AdminSecret: testPassword,
+		},
+		dbClient: mockStaleDB{
+			archived: archived,
+			activity: []db.TargetActivityEntry{
+				{ProjectID: "project1", TargetName: "recorded", LastSubmittedAt: now.AddDate(0, 0, -1)},
+				{ProjectID: "project1", TargetName: "olddeleted", LastSubmittedAt: now.AddDate(0, 0, -200)},
+			},
+		},
+		roleChecker: mockStaleRoleChecker{},
+		readOnly:    newReadOnlyMode(false, mockDB{}, log.NewNopLogger()),
+	}
+}
+
+func TestFindStaleTargets(t *testing.T) {
+	now := time.Now()
+	h := newStaleTargetsHandler(now, nil, nil)
+
+	report, err := h.findStaleTargets(context.Background(), mockStaleCredentialsProvider{}, 90, now, log.NewNopLogger())
+	assert.NoError(t, err)
+
+	assert.Equal(t, responses.StaleTargets{
+		Candidates: []responses.StaleTarget{
+			{
+				ProjectName:  "project1",
+				TargetName:   "olddeleted",
+				RoleArn:      "arn:aws:iam::012345678901:role/olddeleted",
+				LastActivity: fmt.Sprint(now.AddDate(0, 0, -100).Unix()),
+			},
+			{
+				ProjectName: "project1",
+				TargetName:  "undeletable",
+				RoleArn:     "arn:aws:iam::012345678901:role/undeletable",
+			},
+		},
+		Skipped: []responses.SkippedTarget{
+			{
+				ProjectName: "project1",
+				TargetName:  "unchecked",
+				Reason:      "unable to check role",
+			},
+		},
+	}, report)
+}
+
+func TestArchiveStaleTargets(t *testing.T) {
+	var archived []db.ArchivedTargetEntry
+	var deleted []string
+	h := newStaleTargetsHandler(time.Now(), &archived, &deleted)
+
+	body := serialize(map[string]interface{}{
+		"days": 90,
+		"targets": []map[string]string{
+			{"project_name": "project1", "target_name": "olddeleted"},
+			{"project_name": "project1", "target_name": "active"},
+			{"project_name": "project1", "target_name": "undeletable"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/admin/stale-targets/archive", body)
+	req.Header.Add("Authorization", adminAuthHeader)
+	w := httptest.NewRecorder()
+
+	setupRouter(h).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"archived": [
+			{"project_name": "project1", "target_name": "olddeleted", "role_arn": "arn:aws:iam::012345678901:role/olddeleted", "last_activity": "`+fmt.Sprint(time.Now().AddDate(0, 0, -100).Unix())+`"}
+		],
+		"skipped": [
+			{"project_name": "project1", "target_name": "active", "reason": "target is not stale"},
+			{"project_name": "project1", "target_name": "undeletable", "reason": "error deleting target"}
+		]
+	}`, w.Body.String())
+	assert.Equal(t, []string{"olddeleted"}, deleted)
+	if assert.Len(t, archived, 1) {
+		assert.Equal(t, "project1", archived[0].ProjectID)
+		assert.Equal(t, "olddeleted", archived[0].TargetName)
+		assert.JSONEq(t, `{"credential_type":"assumed_role","policy_arns":null,"policy_document":"","role_arn":"arn:aws:iam::012345678901:role/olddeleted"}`, archived[0].Properties)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cello-proj/cello/service/internal/credentials"
+	"github.com/cello-proj/cello/service/internal/workflow"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// Periodically stops workflows which have run past their deadline.
+func (h handler) watchTimeouts(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Accessors of credentials tokens which have been revoked, so revocation
+	// is only retried until it succeeds.
+	revoked := map[string]bool{}
+
+	// Timeouts are still enforced in read-only mode, which only rejects
+	// client requests.
+	for now := range ticker.C {
+		h.stopTimedOutWorkflows(now, revoked)
+	}
+}
+
+// Stops workflows which have run past their deadline and revokes the
+// credentials tokens issued to them. Revocation is retried on each check
+// until it succeeds, including for workflows which could not be stopped.
+// Stopped workflows report a status of 'failed-timeout'.
+func (h handler) stopTimedOutWorkflows(now time.Time, revoked map[string]bool) {
+	l := log.With(h.logger, "op", "stop-timed-out-workflows")
+
+	level.Debug(l).Log("message", "listing workflows with a deadline")
+	statuses, err := h.argo.ListStatus(h.argoCtx, workflow.DeadlineLabel)
+	if err != nil {
+		level.Error(l).Log("message", "error listing workflows", "error", err)
+		return
+	}
+
+	seen := map[string]bool{}
+	var cp credentials.Provider
+	for _, status := range statuses {
+		wl := log.With(l, "workflow", status.Name)
+
+		deadline, ok := workflow.Deadline(status.Labels)
+		if !ok || now.Before(deadline) {
+			continue
+		}
+
+		switch status.Status {
+		case "running", "pending":
+			level.Info(wl).Log("message", "stopping workflow which exceeded its timeout", "deadline", deadline.UTC())
+			if err := h.argo.Stop(h.argoCtx, status.Name); err != nil {
+				level.Error(wl).Log("message", "error stopping workflow", "error", err)
+			}
+		case workflow.StatusFailedTimeout:
+		default:
+			continue
+		}
+
+		accessor := status.Labels[workflow.TokenAccessorLabel]
+		if accessor == "" {
+			level.Warn(wl).Log("message", "no credentials token accessor found, unable to revoke credentials")
+			continue
+		}
+
+		seen[accessor] = true
+		if revoked[accessor] {
+			continue
+		}
+
+		if cp == nil {
+			level.Debug(l).Log("message", "creating credential provider")
+			p, err := h.newCredentialsProvider(credentials.NewAdminAuthorization(h.env.AdminSecret), h.env, http.Header{}, credentials.NewVaultConfig, credentials.NewVaultSvc)
+			if err != nil {
+				level.Error(l).Log("message", "error creating credentials provider", "error", err)
+				continue
+			}
+			cp = p
+		}
+
+		if err := cp.RevokeToken(accessor); err != nil {
+			level.Error(wl).Log("message", "error revoking credentials", "error", err)
+			continue
+		}
+		revoked[accessor] = true
+	}
+
+	// Forget workflows which have finished or are no longer retained by Argo.
+	for accessor := range revoked {
+		if !seen[accessor] {
+			delete(revoked, accessor)
+		}
+	}
+}
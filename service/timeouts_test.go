@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cello-proj/cello/service/internal/credentials"
+	"github.com/cello-proj/cello/service/internal/env"
+	"github.com/cello-proj/cello/service/internal/workflow"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStatusWorkflowSvc struct {
+	mockWorkflowSvc
+	statuses map[string]*workflow.Status
+	stopped  *[]string
+	stopErr  error
+}
+
+func (m mockStatusWorkflowSvc) List(ctx context.Context) ([]string, error) {
+	workflowIDs := []string{}
+	for k := range m.statuses {
+		workflowIDs = append(workflowIDs, k)
+	}
+	return workflowIDs, nil
+}
+
+func (m mockStatusWorkflowSvc) ListStatus(ctx context.Context, labelSelector string) ([]workflow.Status, error) {
+	workflowIDs, _ := m.List(ctx)
+	sort.Strings(workflowIDs)
+
+	statuses := []workflow.Status{}
+	for _, workflowID := range workflowIDs {
+		status := *m.statuses[workflowID]
+		status.Name = workflowID
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m mockStatusWorkflowSvc) Status(ctx context.Context, workflowName string) (*workflow.Status, error) {
+	if status, ok := m.statuses[workflowName]; ok {
+		return status, nil
+	}
+	return nil, fmt.Errorf("workflow " + workflowName + " does not exist!")
+}
+
+func (m mockStatusWorkflowSvc) Stop(ctx context.Context, workflowName string) error {
+	*m.stopped = append(*m.stopped, workflowName)
+	return m.stopErr
+}
+
+type mockTimeoutCredentialsProvider struct {
+	mockCredentialsProvider
+	revoked   *[]string
+	revokeErr error
+}
+
+func (m mockTimeoutCredentialsProvider) RevokeToken(accessor string) error {
+	*m.revoked = append(*m.revoked, accessor)
+	return m.revokeErr
+}
+
+func TestStopTimedOutWorkflows(t *testing.T) {
+	now := time.Now()
+	past := strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)
+	future := strconv.FormatInt(now.Add(time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name           string
+		status         workflow.Status
+		revoked        map[string]bool
+		stopErr        error
+		revokeErr      error
+		providerErr    error
+		wantStopped    []string
+		wantRevoked    []string
+		wantRevokedSet map[string]bool
+	}{
+		{
+			name: "stops running workflow past deadline",
+			status: workflow.Status{Status: "running", Labels: map[string]string{
+				workflow.DeadlineLabel:      past,
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			wantStopped:    []string{"wf-1"},
+			wantRevoked:    []string{"accessor1"},
+			wantRevokedSet: map[string]bool{"accessor1": true},
+		},
+		{
+			name: "ignores running workflow before deadline",
+			status: workflow.Status{Status: "running", Labels: map[string]string{
+				workflow.DeadlineLabel:      future,
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			wantRevokedSet: map[string]bool{},
+		},
+		{
+			name: "ignores finished workflow past deadline",
+			status: workflow.Status{Status: "succeeded", Labels: map[string]string{
+				workflow.DeadlineLabel:      past,
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			wantRevokedSet: map[string]bool{},
+		},
+		{
+			name: "ignores workflow without deadline",
+			status: workflow.Status{Status: "running", Labels: map[string]string{
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			wantRevokedSet: map[string]bool{},
+		},
+		{
+			name: "stops workflow without accessor",
+			status: workflow.Status{Status: "pending", Labels: map[string]string{
+				workflow.DeadlineLabel: past,
+			}},
+			wantStopped:    []string{"wf-1"},
+			wantRevokedSet: map[string]bool{},
+		},
+		{
+			name: "revokes credentials when stop fails",
+			status: workflow.Status{Status: "running", Labels: map[string]string{
+				workflow.DeadlineLabel:      past,
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			stopErr:        errors.New("stop error"),
+			wantStopped:    []string{"wf-1"},
+			wantRevoked:    []string{"accessor1"},
+			wantRevokedSet: map[string]bool{"accessor1": true},
+		},
+		{
+			name: "retries revoke for stopped workflow",
+			status: workflow.Status{Status: workflow.StatusFailedTimeout, Labels: map[string]string{
+				workflow.DeadlineLabel:      past,
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			wantRevoked:    []string{"accessor1"},
+			wantRevokedSet: map[string]bool{"accessor1": true},
+		},
+		{
+			name: "does not revoke again for stopped workflow",
+			status: workflow.Status{Status: workflow.StatusFailedTimeout, Labels: map[string]string{
+				workflow.DeadlineLabel:      past,
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			revoked:        map[string]bool{"accessor1": true},
+			wantRevokedSet: map[string]bool{"accessor1": true},
+		},
+		{
+			name: "revoke error is retried",
+			status: workflow.Status{Status: "running", Labels: map[string]string{
+				workflow.DeadlineLabel:      past,
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			revokeErr:      errors.New("revoke error"),
+			wantStopped:    []string{"wf-1"},
+			wantRevoked:    []string{"accessor1"},
+			wantRevokedSet: map[string]bool{},
+		},
+		{
+			name: "provider error is retried",
+			status: workflow.Status{Status: "running", Labels: map[string]string{
+				workflow.DeadlineLabel:      past,
+				workflow.TokenAccessorLabel: "accessor1",
+			}},
+			providerErr:    errors.New("provider error"),
+			wantStopped:    []string{"wf-1"},
+			wantRevokedSet: map[string]bool{},
+		},
+		{
+			name: "forgets revoked workflows no longer listed",
+			status: workflow.Status{Status: "succeeded", Labels: map[string]string{
+				workflow.DeadlineLabel: past,
+			}},
+			revoked:        map[string]bool{"accessor2": true},
+			wantRevokedSet: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stopped, revokeCalls []string
+			status := tt.status
+
+			revoked := tt.revoked
+			if revoked == nil {
+				revoked = map[string]bool{}
+			}
+
+			h := handler{
+				logger: log.NewNopLogger(),
+				newCredentialsProvider: func(a credentials.Authorization, env env.Vars, h http.Header, f credentials.VaultConfigFn, fn credentials.VaultSvcFn) (credentials.Provider, error) {
+					if tt.providerErr != nil {
+						return nil, tt.providerErr
+					}
+					return mockTimeoutCredentialsProvider{revoked: &revokeCalls, revokeErr: tt.revokeErr}, nil
+				},
+				argo: mockStatusWorkflowSvc{
+					statuses: map[string]*workflow.Status{"wf-1": &status},
+					stopped:  &stopped,
+					stopErr:  tt.stopErr,
+				},
+				argoCtx: context.Background(),
+				env: env.Vars{
+					AdminSecret: testPassword,
+				},
+			}
+
+			h.stopTimedOutWorkflows(now, revoked)
+
+			assert.Equal(t, tt.wantStopped, stopped)
+			assert.Equal(t, tt.wantRevoked, revokeCalls)
+			assert.Equal(t, tt.wantRevokedSet, revoked)
+		})
+	}
+}